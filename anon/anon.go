@@ -0,0 +1,121 @@
+package anon
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Keeper вычисляет анонимные ссылки на отправителей предложений. По ссылке нельзя
+// восстановить Telegram ID без секрета, но можно найти все предложения того же отправителя.
+type Keeper struct {
+	secret []byte
+}
+
+func NewKeeper(secret []byte) *Keeper {
+	return &Keeper{secret: secret}
+}
+
+// Ref возвращает анонимную ссылку на пользователя
+func (k *Keeper) Ref(userID int64) string {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(strconv.FormatInt(userID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Seal шифрует Telegram ID, чтобы бот мог написать автору предложения позже. В отличие
+// от Ref, ID восстанавливается, поэтому запечатанное значение хранится только пока нужно.
+func (k *Keeper) Seal(userID int64) (string, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(strconv.FormatInt(userID, 10)), nil)
+	return hex.EncodeToString(sealed), nil
+}
+
+// Open расшифровывает значение, полученное от Seal
+func (k *Keeper) Open(sealed string) (int64, error) {
+	data, err := hex.DecodeString(sealed)
+	if err != nil {
+		return 0, err
+	}
+
+	aead, err := k.aead()
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < aead.NonceSize() {
+		return 0, errors.New("anon: sealed value is too short")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(plain), 10, 64)
+}
+
+// aead создаёт шифр с ключом, производным от секрета, чтобы не использовать
+// один и тот же ключ для HMAC и шифрования
+func (k *Keeper) aead() (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte("seal"))
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewSecret генерирует случайный секрет для Keeper
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Алфавит кодов без легко путаемых символов 0/O и 1/I
+const ticketAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewTicket генерирует случайный код для отслеживания предложения вида XXXXX-XXXXX.
+// Код не зависит от отправителя, поэтому по нему нельзя определить автора.
+func NewTicket() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, 11)
+	for i, b := range random {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		code = append(code, ticketAlphabet[int(b)%len(ticketAlphabet)])
+	}
+	return string(code), nil
+}
+
+// NormalizeTicket приводит введённый пользователем код к каноничному виду
+func NormalizeTicket(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != 10 {
+		return code
+	}
+	return code[:5] + "-" + code[5:]
+}
@@ -1,10 +1,17 @@
 package bot
 
 import (
-	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
 
+	"telegram-bot/anon"
 	"telegram-bot/database"
 	"telegram-bot/handlers"
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
+	"telegram-bot/settings"
 
 	"github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
@@ -12,19 +19,31 @@ import (
 
 type Bot struct {
 	bot        *telego.Bot
-	db         *database.Database
+	db         database.Storage
+	settings   *settings.Store
+	i18n       *i18n.Localizer
+	anon       *anon.Keeper
 	botHandler *th.BotHandler
+	stop       chan struct{}
 	channelID  int64
 	ownerID    int64
 }
 
-func NewBot(token string, channelID, ownerID int64) (*Bot, error) {
+func NewBot(token, dsn string, channelID, ownerID int64) (*Bot, error) {
 	bot, err := telego.NewBot(token)
 	if err != nil {
 		return nil, err
 	}
 
-	db, err := database.NewDatabase()
+	db, err := database.NewDatabase(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	store := settings.NewStore(db)
+	store.SetDefault(settings.KeyChannelID, strconv.FormatInt(channelID, 10))
+
+	secret, err := senderSecret(store)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +51,10 @@ func NewBot(token string, channelID, ownerID int64) (*Bot, error) {
 	botInstance := &Bot{
 		bot:       bot,
 		db:        db,
+		settings:  store,
+		i18n:      i18n.NewLocalizer(db, store),
+		anon:      anon.NewKeeper([]byte(secret)),
+		stop:      make(chan struct{}),
 		channelID: channelID,
 		ownerID:   ownerID,
 	}
@@ -41,13 +64,36 @@ func NewBot(token string, channelID, ownerID int64) (*Bot, error) {
 	return botInstance, nil
 }
 
+// senderSecret возвращает секрет для анонимных ссылок на отправителей: из SENDER_SECRET
+// или из базы данных, где он создаётся при первом запуске
+func senderSecret(store *settings.Store) (string, error) {
+	if secret := os.Getenv("SENDER_SECRET"); secret != "" {
+		return secret, nil
+	}
+
+	if secret := store.Get(settings.KeySenderSecret); secret != "" {
+		return secret, nil
+	}
+
+	secret, err := anon.NewSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := store.Set(settings.KeySenderSecret, secret); err != nil {
+		return "", err
+	}
+
+	slog.Info("Создан новый секрет для анонимных ссылок на отправителей")
+	return secret, nil
+}
+
 func (b *Bot) initializeOwner() {
 	if !b.db.IsAdmin(b.ownerID) {
 		err := b.db.AddAdmin(b.ownerID, "vstor08")
 		if err != nil {
-			log.Printf("Предупреждение: не удалось добавить владельца: %v", err)
+			slog.Warn("Не удалось добавить владельца", "error", err)
 		} else {
-			log.Printf("✅ Владелец %d добавлен как администратор", b.ownerID)
+			slog.Info("✅ Владелец добавлен как администратор", logger.User(b.ownerID))
 		}
 	}
 }
@@ -55,13 +101,13 @@ func (b *Bot) initializeOwner() {
 func (b *Bot) Start() {
 	updates, err := b.bot.UpdatesViaLongPolling(nil)
 	if err != nil {
-		log.Printf("Ошибка получения обновлений: %v", err)
+		slog.Error("Ошибка получения обновлений", "error", err)
 		return
 	}
 
 	botHandler, err := th.NewBotHandler(b.bot, updates)
 	if err != nil {
-		log.Printf("Ошибка создания обработчика: %v", err)
+		slog.Error("Ошибка создания обработчика", "error", err)
 		return
 	}
 
@@ -70,32 +116,73 @@ func (b *Bot) Start() {
 
 	go botHandler.Start()
 
-	log.Println("🤖 Бот-предложка запущен! Принимает анонимные предложения в ЛС")
+	slog.Info("🤖 Бот-предложка запущен! Принимает анонимные предложения в ЛС")
 }
 
 func (b *Bot) Stop() {
+	close(b.stop)
 	if b.botHandler != nil {
 		b.botHandler.Stop()
 	}
 	b.bot.StopLongPolling()
-	log.Println("Бот остановлен")
+	slog.Info("Бот остановлен")
 }
 
 func (b *Bot) registerHandlers(bh *th.BotHandler) {
 
-	mediaHandler := handlers.NewMediaHandler(b.db)
-	proposalsHandler := handlers.NewProposalsHandler(b.db, mediaHandler, b.channelID, b.ownerID)
-	moderationHandler := handlers.NewModerationHandler(b.db, mediaHandler, b.channelID, b.ownerID)
-	adminHandler := handlers.NewAdminHandler(b.db, b.ownerID)
+	inputs := handlers.NewInputWaiter(b.i18n)
+	mediaHandler := handlers.NewMediaHandler(b.db, b.i18n)
+	renderer := handlers.NewRenderer(b.settings, b.i18n)
+	notifier := handlers.NewNotifier(b.db, b.settings, b.i18n, b.ownerID)
+	proposalsHandler := handlers.NewProposalsHandler(b.db, b.settings, b.i18n, mediaHandler, renderer, notifier, b.anon, b.ownerID)
+	moderationHandler := handlers.NewModerationHandler(b.db, b.settings, b.i18n, renderer, inputs, b.ownerID)
+	adminHandler := handlers.NewAdminHandler(b.db, b.i18n, b.ownerID)
+	settingsHandler := handlers.NewSettingsHandler(b.settings, b.i18n, inputs, b.ownerID)
+	languageHandler := handlers.NewLanguageHandler(b.i18n)
+	statsHandler := handlers.NewStatsHandler(b.db, b.settings, b.i18n, b.ownerID)
+	retentionHandler := handlers.NewRetentionHandler(b.db, b.settings, b.i18n, b.ownerID)
 
 	bh.Handle(proposalsHandler.HandleStartCommand, th.CommandEqual("start"))
 	bh.Handle(moderationHandler.HandleProposalsCommand, th.CommandEqual("proposals"))
 	bh.Handle(adminHandler.HandleAddAdminCommand, th.CommandEqual("addadmin"))
 	bh.Handle(adminHandler.HandleListAdminsCommand, th.CommandEqual("admins"))
-
+	bh.Handle(adminHandler.HandleExportCommand, th.CommandEqual("export"))
+	bh.Handle(retentionHandler.HandlePurgeCommand, th.CommandEqual("purge"))
+	bh.Handle(settingsHandler.HandleSettingsCommand, th.CommandEqual("settings"))
+	bh.Handle(settingsHandler.HandleTextCommand, th.CommandEqual("text"))
+	bh.Handle(languageHandler.HandleLanguageCommand, th.CommandEqual("language"))
+	bh.Handle(proposalsHandler.HandleMyCommand, th.CommandEqual("my"))
+	bh.Handle(proposalsHandler.HandleStatusCommand, th.CommandEqual("status"))
+	bh.Handle(moderationHandler.HandlePostCommand, th.CommandEqual("post"))
+	bh.Handle(moderationHandler.HandleSearchCommand, th.CommandEqual("search"))
+	bh.Handle(notifier.HandleNotifyCommand, th.CommandEqual("notify"))
+	bh.Handle(statsHandler.HandleModStatsCommand, th.CommandEqual("modstats"))
+	bh.Handle(statsHandler.HandleStatsCommand, th.CommandEqual("stats"))
+	bh.Handle(inputs.HandleCancelCommand, th.CommandEqual("cancel"))
+
+	bh.Handle(settingsHandler.HandleCallback, th.CallbackDataPrefix("settings_"))
+	bh.Handle(languageHandler.HandleCallback, th.CallbackDataPrefix("lang_"))
+	bh.Handle(proposalsHandler.HandleDraftCallback, th.CallbackDataPrefix("draft_"))
+	bh.Handle(proposalsHandler.HandleWithdrawCallback, th.CallbackDataPrefix("withdraw_"))
+	bh.Handle(moderationHandler.HandlePostCallback, th.CallbackDataPrefix("post_"))
+	bh.Handle(notifier.HandleNotifyCallback, th.CallbackDataPrefix("notify_"))
+	bh.Handle(moderationHandler.HandleSearchCallback, th.CallbackDataPrefix("search_"))
 	bh.Handle(moderationHandler.HandleCallback, th.AnyCallbackQuery())
 
+	bh.Handle(settingsHandler.HandleSettingInput, inputs.Waiting("settings:"))
+	bh.Handle(moderationHandler.HandleButtonsInput, inputs.Waiting("buttons:"))
+	bh.Handle(moderationHandler.HandlePostEditInput, inputs.Waiting("post_edit:"))
+
 	bh.Handle(proposalsHandler.HandleUserProposal, th.AnyMessage())
+	bh.Handle(proposalsHandler.HandleEditedMessage, th.AnyEditedMessage())
+
+	b.runEvery(time.Minute, func() { proposalsHandler.ExpireDrafts(b.bot) })
+	b.runEvery(time.Hour, func() { proposalsHandler.ExpirePending(b.bot) })
+	b.runEvery(time.Minute, func() { notifier.SendDigests(b.bot) })
+	b.runEvery(time.Minute, func() { notifier.CheckStaleQueue(b.bot) })
+	b.runEvery(time.Hour, func() { statsHandler.SendOwnerReport(b.bot) })
+	b.runEvery(time.Hour, func() { retentionHandler.Purge(b.bot) })
+	b.runEvery(5*time.Second, func() { moderationHandler.PublishScheduled(b.bot) })
 }
 
 // people, please don't post weird/innapropiote stuff, some people are just trying to ⠀⠀⠀⠀⠀⠀⠀⣠⣤⣤⣤⣤⣤⣄⡀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀
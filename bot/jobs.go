@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"time"
+)
+
+// runEvery периодически выполняет задачу в фоне до остановки бота
+func (b *Bot) runEvery(interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				job()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+}
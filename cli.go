@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"telegram-bot/database"
+)
+
+// runCLI выполняет служебные команды без запуска бота:
+//
+//	export <файл.json> - выгрузить базу данных в архив ("-" - в stdout)
+//	import <файл.json> - добавить содержимое архива в базу данных
+//	migrate [down]     - применить миграции и показать их состояние или откатить последнюю
+func runCLI(args []string) error {
+	usage := errors.New("использование: export <файл.json> | import <файл.json> | migrate [down]")
+
+	command, arg := args[0], ""
+	if len(args) == 2 {
+		arg = args[1]
+	}
+	// Команда проверяется до подключения: подключение уже применяет миграции
+	switch {
+	case len(args) > 2:
+		return usage
+	case command == "export" || command == "import":
+		if arg == "" {
+			return usage
+		}
+	case command == "migrate":
+		if arg != "" && arg != "down" {
+			return usage
+		}
+	default:
+		return usage
+	}
+
+	// NewDatabase применяет недостающие миграции при подключении, поэтому migrate
+	// подключается без них: откат не должен сначала накатывать новые миграции
+	connect := database.NewDatabase
+	if command == "migrate" {
+		connect = database.Connect
+	}
+	db, err := connect(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case command == "export":
+		return exportArchive(db, arg)
+	case command == "import":
+		return importArchive(db, arg)
+	case arg == "down":
+		rolledBack, err := db.Rollback()
+		if err != nil {
+			return err
+		}
+		slog.Info("Откачена миграция", "version", rolledBack.Version, "name", rolledBack.Name)
+		return printMigrations(db)
+	default:
+		if _, err := db.Migrate(); err != nil {
+			return err
+		}
+		return printMigrations(db)
+	}
+}
+
+func printMigrations(db *database.Database) error {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		applied := "не применена"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format("02.01.2006 15:04:05")
+		}
+		fmt.Printf("%3d  %-24s %s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
+
+func exportArchive(db database.Storage, path string) error {
+	archive, err := db.Export()
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		return archive.WriteJSON(os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := archive.WriteJSON(file); err != nil {
+		return err
+	}
+	slog.Info("✅ База данных выгружена", "proposals", len(archive.Proposals), "admins", len(archive.Admins), "settings", len(archive.Settings))
+	return file.Close()
+}
+
+func importArchive(db database.Storage, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive, err := database.ReadArchive(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Import(archive)
+	if err != nil {
+		return err
+	}
+	slog.Info("✅ Архив загружен",
+		"proposals", result.Proposals,
+		"duplicates", result.Duplicates,
+		"actions", result.Actions,
+		"admins", result.Admins,
+		"settings", result.Settings,
+		"secret_replaced", result.SecretReplaced,
+	)
+	return nil
+}
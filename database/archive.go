@@ -0,0 +1,268 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"telegram-bot/anon"
+
+	"gorm.io/gorm"
+)
+
+// ArchiveVersion - версия формата архива, увеличивается при несовместимых изменениях
+const ArchiveVersion = 1
+
+// SenderSecretKey - настройка с секретом анонимных ссылок на отправителей. Без этого
+// секрета SenderRef и SenderSeal предложений не сопоставить с авторами.
+const SenderSecretKey = "sender_secret"
+
+// errSecretMismatch - в архиве другой секрет, а в базе уже есть предложения со своими ссылками
+var errSecretMismatch = errors.New("секрет анонимных ссылок в архиве отличается от секрета в базе данных, где уже есть предложения")
+
+// Archive - резервная копия содержимого базы данных. Отдельной таблицы банов в боте нет,
+// поэтому архив содержит предложения с журналом действий, администраторов и настройки.
+// Архив бывает только в JSON: предложения с частями, сообщениями поста и кнопками
+// вложены друг в друга, и CSV пришлось бы делить на несколько файлов, которые импорт
+// должен собирать обратно. Для таблиц есть CSV-выгрузка /stats.
+type Archive struct {
+	Version    int
+	ExportedAt time.Time
+	Proposals  []Message
+	Actions    []ActionLog
+	Admins     []Admin
+	Settings   []Setting
+}
+
+// WriteJSON записывает архив в формате JSON
+func (a *Archive) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// Redact убирает из архива секрет анонимных ссылок и зашифрованные ID авторов: вместе
+// они раскрывают авторов ожидающих предложений
+func (a *Archive) Redact() {
+	settings := make([]Setting, 0, len(a.Settings))
+	for _, setting := range a.Settings {
+		if setting.Key != SenderSecretKey {
+			settings = append(settings, setting)
+		}
+	}
+	a.Settings = settings
+
+	for i := range a.Proposals {
+		a.Proposals[i].SenderSeal = ""
+	}
+}
+
+// ReadArchive читает архив в формате JSON и проверяет его версию
+func ReadArchive(r io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, err
+	}
+	if archive.Version != ArchiveVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия архива: %d", archive.Version)
+	}
+	return &archive, nil
+}
+
+// ImportResult - итог слияния архива с базой данных
+type ImportResult struct {
+	Proposals  int
+	Duplicates int
+	Actions    int
+	Admins     int
+	Settings   int
+	// SecretReplaced - секрет анонимных ссылок базы заменён секретом из архива
+	SecretReplaced bool
+}
+
+// Export выгружает содержимое базы данных в архив
+func (d *Database) Export() (*Archive, error) {
+	archive := &Archive{Version: ArchiveVersion, ExportedAt: time.Now()}
+
+	err := d.db.Preload("Parts", orderParts).Preload("Buttons", orderParts).Preload("Posts", orderParts).
+		Order("id asc").Find(&archive.Proposals).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := d.db.Order("id asc").Find(&archive.Actions).Error; err != nil {
+		return nil, err
+	}
+	if err := d.db.Order("id asc").Find(&archive.Admins).Error; err != nil {
+		return nil, err
+	}
+	if err := d.db.Find(&archive.Settings).Error; err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// Import добавляет содержимое архива в базу данных одной транзакцией. Предложения получают
+// новые ID, уже существующие (с тем же кодом отслеживания или тем же исходным сообщением)
+// пропускаются. Администраторы и настройки добавляются, только если их ещё нет.
+// Исключение - секрет анонимных ссылок: без него импортированные предложения теряют
+// связь с авторами, поэтому он заменяет секрет базы, если в ней ещё нет предложений,
+// а иначе импорт отклоняется.
+func (d *Database) Import(archive *Archive) (ImportResult, error) {
+	var result ImportResult
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		replaced, err := importSecret(tx, archive)
+		if err != nil {
+			return err
+		}
+		result.SecretReplaced = replaced
+
+		ids := make(map[uint]uint)
+
+		for _, proposal := range archive.Proposals {
+			if existing, ok, err := findDuplicate(tx, proposal); err != nil {
+				return err
+			} else if ok {
+				ids[proposal.ID] = existing
+				result.Duplicates++
+				continue
+			}
+
+			oldID := proposal.ID
+			proposal.ID = 0
+			if proposal.Ticket == "" {
+				ticket, err := anon.NewTicket()
+				if err != nil {
+					return err
+				}
+				proposal.Ticket = ticket
+			}
+			for i := range proposal.Parts {
+				proposal.Parts[i].ID, proposal.Parts[i].ProposalID = 0, 0
+			}
+			for i := range proposal.Buttons {
+				proposal.Buttons[i].ID, proposal.Buttons[i].ProposalID = 0, 0
+			}
+			for i := range proposal.Posts {
+				proposal.Posts[i].ID, proposal.Posts[i].ProposalID = 0, 0
+			}
+
+			if err := tx.Create(&proposal).Error; err != nil {
+				return err
+			}
+			ids[oldID] = proposal.ID
+			result.Proposals++
+		}
+
+		for _, action := range archive.Actions {
+			newID, ok := ids[action.ProposalID]
+			if !ok {
+				continue
+			}
+			var count int64
+			err := tx.Model(&ActionLog{}).
+				Where("proposal_id = ? AND actor_id = ? AND action = ? AND created_at = ?", newID, action.ActorID, action.Action, action.CreatedAt).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			action.ID, action.ProposalID = 0, newID
+			if err := tx.Create(&action).Error; err != nil {
+				return err
+			}
+			result.Actions++
+		}
+
+		for _, admin := range archive.Admins {
+			var count int64
+			if err := tx.Model(&Admin{}).Where("user_id = ?", admin.UserID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			admin.ID = 0
+			if err := tx.Create(&admin).Error; err != nil {
+				return err
+			}
+			result.Admins++
+		}
+
+		for _, setting := range archive.Settings {
+			var count int64
+			if err := tx.Model(&Setting{}).Where("key = ?", setting.Key).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(&setting).Error; err != nil {
+				return err
+			}
+			result.Settings++
+		}
+		return nil
+	})
+	return result, err
+}
+
+// importSecret переносит секрет анонимных ссылок из архива в базу
+func importSecret(tx *gorm.DB, archive *Archive) (bool, error) {
+	var secret string
+	for _, setting := range archive.Settings {
+		if setting.Key == SenderSecretKey {
+			secret = setting.Value
+		}
+	}
+	if secret == "" {
+		return false, nil
+	}
+
+	var current []Setting
+	if err := tx.Where("key = ?", SenderSecretKey).Limit(1).Find(&current).Error; err != nil {
+		return false, err
+	}
+	if len(current) == 0 || current[0].Value == secret {
+		// Отсутствующий секрет добавится вместе с остальными настройками
+		return false, nil
+	}
+
+	var count int64
+	if err := tx.Model(&Message{}).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, errSecretMismatch
+	}
+
+	err := tx.Model(&Setting{}).Where("key = ?", SenderSecretKey).Update("value", secret).Error
+	return err == nil, err
+}
+
+// findDuplicate ищет в базе предложение из архива: по коду отслеживания, а для старых
+// предложений без кода - по отправителю, исходному сообщению и времени поступления
+func findDuplicate(tx *gorm.DB, proposal Message) (uint, bool, error) {
+	query := tx.Model(&Message{})
+	if proposal.Ticket != "" {
+		query = query.Where("ticket = ?", proposal.Ticket)
+	} else {
+		query = query.Where("sender_ref = ? AND message_id = ? AND created_at = ?", proposal.SenderRef, proposal.MessageID, proposal.CreatedAt)
+	}
+
+	var existing []Message
+	if err := query.Limit(1).Find(&existing).Error; err != nil {
+		return 0, false, err
+	}
+	if len(existing) == 0 {
+		return 0, false, nil
+	}
+	return existing[0].ID, true, nil
+}
@@ -1,10 +1,13 @@
 package database
 
 import (
+	"strings"
 	"time"
 
 	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Message struct {
@@ -16,6 +19,73 @@ type Message struct {
 	CreatedAt   time.Time
 	Status      string `gorm:"default:'pending'"`
 	ChannelID   int64
+	// SenderRef - анонимная ссылка на отправителя (HMAC от Telegram ID), см. пакет anon
+	SenderRef string `gorm:"index;size:64"`
+	// SenderSeal - зашифрованный Telegram ID автора для уведомления об истечении срока,
+	// см. anon.Keeper.Seal. Стирается, как только предложение рассмотрено.
+	SenderSeal string
+	// Ticket - код, по которому автор может узнать статус предложения через /status.
+	// Уникальный индекс создаётся миграцией unique_tickets.
+	Ticket    string `gorm:"size:16"`
+	DecidedAt *time.Time
+	// ForwardSource - источник пересланного сообщения, пусто для собственных сообщений
+	ForwardSource string `gorm:"size:255"`
+	// StagingChatID и StagingMessageID указывают на копию исходного сообщения в служебном
+	// чате, из которой предложение публикуется через copyMessage
+	StagingChatID    int64
+	StagingMessageID int
+	// PublishAt - время отложенной публикации одобренного предложения (статус "scheduled")
+	PublishAt *time.Time
+	// EditedAt - время последнего изменения предложения автором
+	EditedAt *time.Time
+	Parts    []MessagePart `gorm:"foreignKey:ProposalID"`
+	Buttons  []PostButton  `gorm:"foreignKey:ProposalID"`
+	Posts    []ChannelPost `gorm:"foreignKey:ProposalID"`
+}
+
+// MessagePart - одно из сообщений составного предложения (MediaType "composite")
+type MessagePart struct {
+	ID         uint `gorm:"primaryKey"`
+	ProposalID uint `gorm:"index;not null"`
+	// MessageID - ID исходного сообщения в чате автора, нужен для обработки правок
+	MessageID   int
+	Position    int
+	MediaType   string `gorm:"size:50"`
+	MediaFileID string
+	Text        string
+}
+
+// PostButton - кнопка-ссылка, которую модератор добавил к посту перед публикацией
+type PostButton struct {
+	ID         uint `gorm:"primaryKey"`
+	ProposalID uint `gorm:"index;not null"`
+	Position   int
+	Text       string
+	URL        string
+}
+
+// ChannelPost - сообщение, отправленное в канал при публикации предложения
+type ChannelPost struct {
+	ID         uint `gorm:"primaryKey"`
+	ProposalID uint `gorm:"index;not null"`
+	Position   int
+	ChatID     int64
+	MessageID  int
+	// TextKind - "text" или "caption", если сообщение несёт текст поста, иначе пусто
+	TextKind    string `gorm:"size:16"`
+	HasKeyboard bool
+	// Exact - сообщение опубликовано точной копией, без оформления
+	Exact bool
+}
+
+// ActionLog - журнал действий модераторов с предложениями
+type ActionLog struct {
+	ID         uint   `gorm:"primaryKey"`
+	ProposalID uint   `gorm:"index;not null"`
+	ActorID    int64  `gorm:"index"`
+	Action     string `gorm:"size:32"`
+	Details    string
+	CreatedAt  time.Time
 }
 
 type Admin struct {
@@ -24,21 +94,94 @@ type Admin struct {
 	UserName string
 }
 
+type Setting struct {
+	Key   string `gorm:"primaryKey;size:100"`
+	Value string
+}
+
+type UserLanguage struct {
+	UserID int64  `gorm:"primaryKey;autoIncrement:false"`
+	Lang   string `gorm:"size:10"`
+}
+
+// NotifyPreference - настройки уведомлений модератора о новых предложениях
+type NotifyPreference struct {
+	UserID int64 `gorm:"primaryKey;autoIncrement:false"`
+	// Mode - "immediate", "digest" или "muted"
+	Mode          string `gorm:"size:16"`
+	DigestMinutes int
+	// QuietFrom и QuietTo - часы тишины по времени сервера, совпадающие значения отключают тишину
+	QuietFrom int
+	QuietTo   int
+	// NotifiedAt - время последнего уведомления, более новые предложения модератор ещё не видел
+	NotifiedAt *time.Time
+}
+
+// Database - реализация Storage на GORM для SQLite и PostgreSQL
 type Database struct {
 	db *gorm.DB
 }
 
-func NewDatabase() (*Database, error) {
-	db, err := gorm.Open(sqlite.Open("bot.db"), &gorm.Config{})
+// DefaultDSN - файл SQLite, который используется, если строка подключения не задана
+const DefaultDSN = "bot.db"
+
+// NewDatabase подключается к базе данных по строке подключения: postgres://... или
+// "host=... dbname=..." выбирают PostgreSQL, всё остальное считается путём к файлу SQLite
+func NewDatabase(dsn string) (*Database, error) {
+	d, err := Connect(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Migrate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Connect подключается к базе данных, не применяя миграции. Нужен команде migrate,
+// которая сама решает, применять миграции или откатывать.
+func Connect(dsn string) (*Database, error) {
+	if dsn == "" {
+		dsn = DefaultDSN
+	}
+	return open(dialector(dsn), 0)
+}
+
+// NewMemoryDatabase создаёт временную базу SQLite в памяти, которая пропадает при закрытии
+func NewMemoryDatabase() (*Database, error) {
+	// У каждого соединения SQLite в памяти своя база, поэтому соединение должно быть одно
+	d, err := open(sqlite.Open(":memory:"), 1)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := d.Migrate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func dialector(dsn string) gorm.Dialector {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") || strings.Contains(dsn, "host=") {
+		return postgres.Open(dsn)
+	}
+	return sqlite.Open(dsn)
+}
 
-	err = db.AutoMigrate(&Message{}, &Admin{})
+// open подключается к базе; maxConns = 0 не ограничивает пул
+func open(dialector gorm.Dialector, maxConns int) (*Database, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newGormLogger()})
 	if err != nil {
 		return nil, err
 	}
 
+	if maxConns > 0 {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(maxConns)
+	}
+
 	return &Database{db: db}, nil
 }
 
@@ -48,24 +191,336 @@ func (d *Database) SaveMessage(msg *Message) error {
 
 func (d *Database) GetPendingMessages() ([]Message, error) {
 	var messages []Message
-	err := d.db.Where("status = ?", "pending").Order("created_at asc").Find(&messages).Error
+	err := d.db.Preload("Parts", orderParts).Preload("Buttons", orderParts).Where("status = ?", "pending").Order("created_at asc").Find(&messages).Error
+	return messages, err
+}
+
+// GetPendingOlderThan возвращает ожидающие предложения, поступившие раньше cutoff
+func (d *Database) GetPendingOlderThan(cutoff time.Time) ([]Message, error) {
+	var messages []Message
+	err := d.db.Where("status = ? AND created_at < ?", "pending", cutoff).Order("created_at asc").Find(&messages).Error
+	return messages, err
+}
+
+// UpdateMessageStatus переводит предложение из статуса from в статус to.
+// Возвращает false, если предложение уже находится в другом статусе.
+// Время решения ставится только при выходе из очереди: удаление опубликованного поста
+// и его откат не меняют время, от которого считаются статистика и сроки хранения.
+func (d *Database) UpdateMessageStatus(id uint, from, to string) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	switch {
+	case to == "pending":
+		updates["decided_at"] = nil
+	case from == "pending" || from == "scheduled":
+		updates["decided_at"] = time.Now()
+	}
+	if from == "scheduled" {
+		updates["publish_at"] = nil
+	}
+	if to != "pending" && to != "scheduled" {
+		updates["sender_seal"] = ""
+	}
+
+	result := d.db.Model(&Message{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReturnToPending возвращает в очередь предложение, которое не удалось опубликовать.
+// Зашифрованный ID автора стирается при выходе из очереди, поэтому он записывается обратно.
+// Возвращает false, если предложение уже не находится в статусе from.
+func (d *Database) ReturnToPending(id uint, from, senderSeal string) (bool, error) {
+	result := d.db.Model(&Message{}).Where("id = ? AND status = ?", id, from).Updates(map[string]interface{}{
+		"status":      "pending",
+		"decided_at":  nil,
+		"publish_at":  nil,
+		"sender_seal": senderSeal,
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ScheduleMessage откладывает публикацию ожидающего предложения до publishAt.
+// Возвращает false, если предложение уже рассмотрено.
+func (d *Database) ScheduleMessage(id uint, publishAt time.Time) (bool, error) {
+	result := d.db.Model(&Message{}).Where("id = ? AND status = ?", id, "pending").Updates(map[string]interface{}{
+		"status":     "scheduled",
+		"decided_at": time.Now(),
+		"publish_at": publishAt,
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDueMessages возвращает предложения, время отложенной публикации которых наступило
+func (d *Database) GetDueMessages(now time.Time) ([]Message, error) {
+	var messages []Message
+	err := d.db.Preload("Parts", orderParts).Preload("Buttons", orderParts).
+		Where("status = ? AND publish_at <= ?", "scheduled", now).
+		Order("publish_at asc").
+		Find(&messages).Error
 	return messages, err
 }
 
-func (d *Database) UpdateMessageStatus(messageID int, status string) error {
-	return d.db.Model(&Message{}).Where("message_id = ?", messageID).Update("status", status).Error
+func (d *Database) DeleteMessage(id uint) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("proposal_id = ?", id).Delete(&MessagePart{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&PostButton{}, &ChannelPost{}, &ActionLog{}} {
+			if err := tx.Where("proposal_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&Message{}, id).Error
+	})
+}
+
+func (d *Database) GetMessageByID(id uint) (Message, error) {
+	var message Message
+	err := d.db.Preload("Parts", orderParts).Preload("Buttons", orderParts).Preload("Posts", orderParts).First(&message, id).Error
+	return message, err
+}
+
+// SaveChannelPosts запоминает сообщения, из которых состоит опубликованный пост
+func (d *Database) SaveChannelPosts(proposalID uint, posts []ChannelPost) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	for i := range posts {
+		posts[i].ProposalID = proposalID
+		posts[i].Position = i
+	}
+	return d.db.Create(&posts).Error
+}
+
+// DeleteChannelPosts забывает сообщения поста, удалённые из канала
+func (d *Database) DeleteChannelPosts(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.db.Delete(&ChannelPost{}, ids).Error
+}
+
+// UpdateMessageText заменяет текст предложения, например после правки опубликованного поста
+func (d *Database) UpdateMessageText(id uint, text string) error {
+	return d.db.Model(&Message{}).Where("id = ?", id).Update("message_text", text).Error
+}
+
+// ClearStagedCopy забывает копию предложения в служебном чате после её удаления
+func (d *Database) ClearStagedCopy(id uint) error {
+	return d.db.Model(&Message{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"staging_chat_id":    0,
+		"staging_message_id": 0,
+	}).Error
 }
 
-func (d *Database) DeleteMessage(messageID int) error {
-	return d.db.Delete(&Message{}, &Message{MessageID: messageID}).Error
+// LogAction записывает действие модератора с предложением
+func (d *Database) LogAction(proposalID uint, actorID int64, action, details string) error {
+	entry := ActionLog{
+		ProposalID: proposalID,
+		ActorID:    actorID,
+		Action:     action,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}
+	return d.db.Create(&entry).Error
+}
+
+// Decision - решение модератора по предложению, используется в статистике
+type Decision struct {
+	ActorID     int64
+	Action      string
+	DecidedAt   time.Time
+	SubmittedAt time.Time
+}
+
+// GetDecisions возвращает одобрения и отклонения предложений, сделанные после since.
+// Одобрение, отменённое в окне отмены, не учитывается: следующая за ним запись
+// одобрения или отмены по тому же предложению - "undo".
+func (d *Database) GetDecisions(since time.Time) ([]Decision, error) {
+	undone := d.db.Table("action_logs AS undo").
+		Select("1").
+		Where("undo.proposal_id = action_logs.proposal_id AND undo.action = ? AND undo.id > action_logs.id", "undo").
+		Where("NOT EXISTS (?)", d.db.Table("action_logs AS later").
+			Select("1").
+			Where("later.proposal_id = action_logs.proposal_id AND later.action = ? AND later.id > action_logs.id AND later.id < undo.id", "approve"))
+
+	var decisions []Decision
+	err := d.db.Table("action_logs").
+		Select("action_logs.actor_id, action_logs.action, action_logs.created_at AS decided_at, messages.created_at AS submitted_at").
+		Joins("JOIN messages ON messages.id = action_logs.proposal_id").
+		Where("action_logs.action IN ? AND action_logs.created_at >= ?", []string{"approve", "reject"}, since).
+		Where("action_logs.action <> ? OR NOT EXISTS (?)", "approve", undone).
+		Order("action_logs.created_at asc").
+		Scan(&decisions).Error
+	return decisions, err
+}
+
+// GroupCount - число предложений с одинаковым значением поля
+type GroupCount struct {
+	Value string
+	Count int64
+}
+
+// CountByStatus группирует предложения, поступившие после since, по статусу
+func (d *Database) CountByStatus(since time.Time) ([]GroupCount, error) {
+	return d.countBy("status", "count desc", since)
+}
+
+// CountByMediaType группирует предложения, поступившие после since, по типу содержимого
+func (d *Database) CountByMediaType(since time.Time) ([]GroupCount, error) {
+	return d.countBy("media_type", "count desc", since)
+}
+
+// CountByDay группирует предложения, поступившие после since, по дню поступления (ГГГГ-ММ-ДД).
+// Дни в SQLite считаются по местному времени, в PostgreSQL - по часовому поясу сессии.
+func (d *Database) CountByDay(since time.Time) ([]GroupCount, error) {
+	return d.countBy(d.formatTime("created_at", "%Y-%m-%d", "YYYY-MM-DD"), "value asc", since)
+}
+
+// CountByHour группирует предложения, поступившие после since, по часу поступления (00-23)
+func (d *Database) CountByHour(since time.Time) ([]GroupCount, error) {
+	return d.countBy(d.formatTime("created_at", "%H", "HH24"), "value asc", since)
+}
+
+// AverageWait возвращает среднее время от поступления до решения для предложений,
+// поступивших после since и уже рассмотренных
+func (d *Database) AverageWait(since time.Time) (time.Duration, error) {
+	seconds := "AVG((julianday(decided_at) - julianday(created_at)) * 86400)"
+	if d.db.Dialector.Name() == "postgres" {
+		seconds = "AVG(EXTRACT(EPOCH FROM decided_at - created_at))"
+	}
+
+	var average *float64
+	err := d.db.Model(&Message{}).
+		Select(seconds).
+		Where("created_at >= ? AND decided_at IS NOT NULL", since).
+		Scan(&average).Error
+	if err != nil || average == nil {
+		return 0, err
+	}
+	return time.Duration(*average * float64(time.Second)), nil
+}
+
+// countBy выполняет GROUP BY по выражению над таблицей messages; expr и order передаются
+// только из кода
+func (d *Database) countBy(expr, order string, since time.Time) ([]GroupCount, error) {
+	var counts []GroupCount
+	err := d.db.Model(&Message{}).
+		Select(expr+" AS value, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group(expr).
+		Order(order).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// formatTime возвращает выражение SQL, форматирующее время в столбце column: формат
+// задаётся для strftime в SQLite и для to_char в PostgreSQL
+func (d *Database) formatTime(column, sqliteLayout, postgresLayout string) string {
+	if d.db.Dialector.Name() == "postgres" {
+		return "to_char(" + column + ", '" + postgresLayout + "')"
+	}
+	return "strftime('" + sqliteLayout + "', " + column + ", 'localtime')"
+}
+
+// GetActions возвращает последние действия с предложением, начиная с новых
+func (d *Database) GetActions(proposalID uint, limit int) ([]ActionLog, error) {
+	var actions []ActionLog
+	err := d.db.Where("proposal_id = ?", proposalID).Order("id desc").Limit(limit).Find(&actions).Error
+	return actions, err
+}
+
+// SetPostButtons заменяет кнопки, которые будут добавлены к посту. Кнопки меняются только
+// у ожидающего предложения: иначе возвращается false и ничего не записывается.
+func (d *Database) SetPostButtons(proposalID uint, buttons []PostButton) (bool, error) {
+	updated := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		// Запись в строку предложения блокирует её до конца транзакции, поэтому одобрение
+		// не проскочит между проверкой статуса и записью кнопок
+		result := tx.Model(&Message{}).Where("id = ? AND status = ?", proposalID, "pending").Update("status", gorm.Expr("status"))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+
+		if err := tx.Where("proposal_id = ?", proposalID).Delete(&PostButton{}).Error; err != nil {
+			return err
+		}
+		for i := range buttons {
+			buttons[i].ProposalID = proposalID
+			buttons[i].Position = i
+		}
+		if len(buttons) == 0 {
+			return nil
+		}
+		return tx.Create(&buttons).Error
+	})
+	return updated && err == nil, err
+}
+
+// GetMessageBySource ищет предложение отправителя по ID исходного сообщения в его чате,
+// в том числе среди сообщений, из которых собрано составное предложение
+func (d *Database) GetMessageBySource(senderRef string, messageID int) (Message, error) {
+	var message Message
+	parts := d.db.Model(&MessagePart{}).Select("proposal_id").Where("message_id = ?", messageID)
+	err := d.db.Preload("Parts", orderParts).Preload("Buttons", orderParts).
+		Where("sender_ref = ?", senderRef).
+		Where(d.db.Where("message_id = ?", messageID).Or("id IN (?)", parts)).
+		Order("id desc").
+		First(&message).Error
+	return message, err
 }
 
-func (d *Database) GetMessageByID(messageID int) (Message, error) {
+// UpdatePendingContent заменяет содержимое предложения и его частей, пока оно ожидает
+// модерации. Возвращает false, если предложение уже рассмотрено.
+func (d *Database) UpdatePendingContent(msg *Message) (bool, error) {
+	updated := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Message{}).Where("id = ? AND status = ?", msg.ID, "pending").Updates(map[string]interface{}{
+			"message_text":       msg.MessageText,
+			"media_type":         msg.MediaType,
+			"media_file_id":      msg.MediaFileID,
+			"edited_at":          msg.EditedAt,
+			"staging_chat_id":    msg.StagingChatID,
+			"staging_message_id": msg.StagingMessageID,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+
+		for _, part := range msg.Parts {
+			err := tx.Model(&MessagePart{}).Where("id = ?", part.ID).Updates(map[string]interface{}{
+				"media_type":    part.MediaType,
+				"media_file_id": part.MediaFileID,
+				"text":          part.Text,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// GetMessageByTicket ищет предложение по коду отслеживания
+func (d *Database) GetMessageByTicket(ticket string) (Message, error) {
 	var message Message
-	err := d.db.First(&message, &Message{MessageID: messageID}).Error
+	err := d.db.Where("ticket = ?", ticket).First(&message).Error
 	return message, err
 }
 
+// GetMessagesBySender возвращает последние предложения отправителя по анонимной ссылке
+func (d *Database) GetMessagesBySender(senderRef string, limit int) ([]Message, error) {
+	var messages []Message
+	err := d.db.Preload("Posts", orderParts).Where("sender_ref = ?", senderRef).Order("created_at desc").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func orderParts(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc")
+}
+
 func (d *Database) IsAdmin(userID int64) bool {
 	err := d.db.First(&Admin{}, &Admin{UserID: userID}).Error
 	return err == nil
@@ -88,3 +543,82 @@ func (d *Database) GetAdmins() ([]Admin, error) {
 	err := d.db.Find(&admins).Error
 	return admins, err
 }
+
+func (d *Database) GetSettings() ([]Setting, error) {
+	var settings []Setting
+	err := d.db.Find(&settings).Error
+	return settings, err
+}
+
+func (d *Database) SetSetting(key, value string) error {
+	setting := Setting{Key: key, Value: value}
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value"}),
+	}).Create(&setting).Error
+}
+
+func (d *Database) DeleteSetting(key string) error {
+	return d.db.Where("key = ?", key).Delete(&Setting{}).Error
+}
+
+// GetUserLanguage возвращает выбранный пользователем язык или пустую строку
+func (d *Database) GetUserLanguage(userID int64) (string, error) {
+	var languages []UserLanguage
+	err := d.db.Where("user_id = ?", userID).Limit(1).Find(&languages).Error
+	if err != nil || len(languages) == 0 {
+		return "", err
+	}
+	return languages[0].Lang, nil
+}
+
+func (d *Database) SetUserLanguage(userID int64, lang string) error {
+	language := UserLanguage{UserID: userID, Lang: lang}
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"lang"}),
+	}).Create(&language).Error
+}
+
+// GetNotifyPreference возвращает настройки уведомлений модератора; found = false, если он их не менял
+func (d *Database) GetNotifyPreference(userID int64) (NotifyPreference, bool, error) {
+	var preferences []NotifyPreference
+	err := d.db.Where("user_id = ?", userID).Limit(1).Find(&preferences).Error
+	if err != nil || len(preferences) == 0 {
+		return NotifyPreference{UserID: userID}, false, err
+	}
+	return preferences[0], true, nil
+}
+
+func (d *Database) SaveNotifyPreference(preference NotifyPreference) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"mode", "digest_minutes", "quiet_from", "quiet_to"}),
+	}).Create(&preference).Error
+}
+
+// SetNotifiedAt запоминает время последнего уведомления модератора
+func (d *Database) SetNotifiedAt(userID int64, notifiedAt time.Time) error {
+	preference := NotifyPreference{UserID: userID, NotifiedAt: &notifiedAt}
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notified_at"}),
+	}).Create(&preference).Error
+}
+
+// CountPendingSince возвращает число ожидающих предложений, поступивших после since
+func (d *Database) CountPendingSince(since time.Time) (int64, error) {
+	var count int64
+	err := d.db.Model(&Message{}).Where("status = ? AND created_at > ?", "pending", since).Count(&count).Error
+	return count, err
+}
+
+// GetOldestPending возвращает самое старое ожидающее предложение; found = false, если очередь пуста
+func (d *Database) GetOldestPending() (Message, bool, error) {
+	var messages []Message
+	err := d.db.Where("status = ?", "pending").Order("created_at asc").Limit(1).Find(&messages).Error
+	if err != nil || len(messages) == 0 {
+		return Message{}, false, err
+	}
+	return messages[0], true, nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"telegram-bot/logger"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQuery - запросы дольше этого времени попадают в журнал как предупреждения
+const slowQuery = 200 * time.Millisecond
+
+// gormLogger направляет журнал GORM в slog. В режиме приватности запросы выводятся
+// с плейсхолдерами вместо значений: в значениях бывают текст предложений и ссылки на авторов.
+type gormLogger struct {
+	level gormlogger.LogLevel
+}
+
+func newGormLogger() gormLogger {
+	return gormLogger{level: gormlogger.Warn}
+}
+
+func (l gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace выводит ошибочные и медленные запросы, а при уровне debug - все запросы.
+// Отсутствие записи не считается ошибкой: так, например, проверяется IsAdmin.
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "Ошибка запроса к базе данных", "sql", sql, "rows", rows, "elapsed", elapsed, "error", err)
+	case elapsed > slowQuery && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "Медленный запрос к базе данных", "sql", sql, "rows", rows, "elapsed", elapsed)
+	case slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "Запрос к базе данных", "sql", sql, "rows", rows, "elapsed", elapsed)
+	}
+}
+
+// ParamsFilter убирает значения из текста запроса, если включён режим приватности
+func (l gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if logger.Private() {
+		return sql, nil
+	}
+	return sql, params
+}
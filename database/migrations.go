@@ -0,0 +1,231 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"telegram-bot/anon"
+
+	"gorm.io/gorm"
+)
+
+// SchemaMigration - запись о применённой миграции схемы
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// migration - шаг изменения схемы или данных. Шаг выполняется в транзакции вместе с
+// записью в schema_migrations. down может отсутствовать, тогда откат невозможен.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *gorm.DB) error
+	down    func(tx *gorm.DB) error
+}
+
+// migrations - упорядоченный список миграций. Новые миграции добавляются только в конец,
+// применённые миграции не меняются. Миграции работают с замороженными типами схемы
+// (v1Message и т.д.) или с SQL, но не с текущими моделями.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial_schema",
+		// Схема заморожена в schema_v1.go. Базы, созданные до появления миграций, уже
+		// содержат эти таблицы - AutoMigrate в этом случае ничего не меняет
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v1Message{}, &v1MessagePart{}, &v1PostButton{}, &v1ChannelPost{}, &v1ActionLog{}, &v1Admin{}, &v1Setting{}, &v1UserLanguage{}, &v1NotifyPreference{})
+		},
+	},
+	{
+		version: 2,
+		name:    "unique_tickets",
+		up:      migrateUniqueTickets,
+		down: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_messages_ticket_unique").Error
+		},
+	},
+	{
+		version: 3,
+		name:    "proposal_search",
+		up:      migrateProposalSearch,
+		down: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "sqlite" {
+				return nil
+			}
+			for _, statement := range []string{
+				"DROP TRIGGER IF EXISTS messages_fts_insert",
+				"DROP TRIGGER IF EXISTS messages_fts_delete",
+				"DROP TRIGGER IF EXISTS messages_fts_update",
+				"DROP TABLE IF EXISTS messages_fts",
+			} {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// migrateUniqueTickets выдаёт коды отслеживания предложениям, поступившим до их появления,
+// и делает код уникальным, чтобы по нему однозначно находилось одно предложение
+func migrateUniqueTickets(tx *gorm.DB) error {
+	var ids []uint
+	if err := tx.Model(&v1Message{}).Where("ticket = ? OR ticket IS NULL", "").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ticket, err := anon.NewTicket()
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&v1Message{}).Where("id = ?", id).Update("ticket", ticket).Error; err != nil {
+			return err
+		}
+	}
+
+	if tx.Migrator().HasIndex(&v1Message{}, "idx_messages_ticket") {
+		if err := tx.Migrator().DropIndex(&v1Message{}, "idx_messages_ticket"); err != nil {
+			return err
+		}
+	}
+	return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_ticket_unique ON messages (ticket)").Error
+}
+
+// migrateProposalSearch создаёт в SQLite полнотекстовый индекс FTS5 по тексту предложений,
+// который поддерживается триггерами. В PostgreSQL поиск выполняется через ILIKE.
+func migrateProposalSearch(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "sqlite" {
+		return nil
+	}
+
+	for _, statement := range []string{
+		"CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(message_text, content='messages', content_rowid='id')",
+		`CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages BEGIN
+			INSERT INTO messages_fts(rowid, message_text) VALUES (new.id, new.message_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages BEGIN
+			INSERT INTO messages_fts(messages_fts, rowid, message_text) VALUES ('delete', old.id, old.message_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE OF message_text ON messages BEGIN
+			INSERT INTO messages_fts(messages_fts, rowid, message_text) VALUES ('delete', old.id, old.message_text);
+			INSERT INTO messages_fts(rowid, message_text) VALUES (new.id, new.message_text);
+		END`,
+		"INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')",
+	} {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrationStatus - состояние миграции для команды migrate
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrate применяет недостающие миграции по порядку и возвращает их число
+func (d *Database) Migrate() (int, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+
+		err := d.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.version, Name: m.name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("миграция %d (%s): %w", m.version, m.name, err)
+		}
+
+		slog.Info("Применена миграция", "version", m.version, "name", m.name)
+		count++
+	}
+	return count, nil
+}
+
+// Rollback откатывает последнюю применённую миграцию
+func (d *Database) Rollback() (SchemaMigration, error) {
+	var last SchemaMigration
+	if err := d.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return last, err
+	}
+	err := d.db.Order("version desc").Limit(1).Find(&last).Error
+	if err != nil {
+		return last, err
+	}
+	if last.Version == 0 {
+		return last, errors.New("нет применённых миграций")
+	}
+
+	for _, m := range migrations {
+		if m.version != last.Version {
+			continue
+		}
+		if m.down == nil {
+			return last, fmt.Errorf("миграцию %d (%s) нельзя откатить", m.version, m.name)
+		}
+
+		err := d.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", m.version).Error
+		})
+		return last, err
+	}
+	return last, errors.New("применённая миграция отсутствует в списке")
+}
+
+// MigrationStatus возвращает список миграций с отметкой о применении
+func (d *Database) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.version, Name: m.name}
+		if record, ok := applied[m.version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// appliedMigrations возвращает применённые миграции, при необходимости создавая их таблицу
+func (d *Database) appliedMigrations() (map[int]SchemaMigration, error) {
+	if err := d.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var records []SchemaMigration
+	if err := d.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
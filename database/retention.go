@@ -0,0 +1,170 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy - сроки хранения данных в днях; нулевой срок отключает очистку
+type RetentionPolicy struct {
+	// RejectedDays - через сколько дней стирается содержимое отклонённых, отозванных
+	// и просроченных предложений
+	RejectedDays int
+	// PublishedDays - через сколько дней у опубликованных предложений удаляются связь
+	// с автором и ID файлов; текст поста остаётся, он и так публичен
+	PublishedDays int
+	// SenderDays - через сколько дней удаляются анонимные ссылки на отправителей
+	// рассмотренных предложений
+	SenderDays int
+}
+
+// PurgeReport - число предложений, затронутых очисткой
+type PurgeReport struct {
+	RejectedWiped       int
+	PublishedAnonymised int
+	SendersForgotten    int
+	// Staged - копии очищенных предложений в служебном чате. Хранилище их только забывает,
+	// удалить сами сообщения должен вызывающий код через бота.
+	Staged []StagedCopy
+}
+
+// Empty сообщает, что очистка ничего не затронула
+func (r PurgeReport) Empty() bool {
+	return r.RejectedWiped == 0 && r.PublishedAnonymised == 0 && r.SendersForgotten == 0 && len(r.Staged) == 0
+}
+
+// StagedCopy - копия предложения в служебном чате, см. Message.StagingMessageID
+type StagedCopy struct {
+	ProposalID uint
+	ChatID     int64
+	MessageID  int
+}
+
+var errDryRun = errors.New("dry run")
+
+// Purge применяет политику хранения к предложениям, рассмотренным до now минус срок.
+// При dryRun изменения выполняются в транзакции и откатываются, поэтому отчёт точно
+// совпадает с тем, что будет удалено.
+func (d *Database) Purge(policy RetentionPolicy, now time.Time, dryRun bool) (PurgeReport, error) {
+	var report PurgeReport
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if policy.RejectedDays > 0 {
+			if report.RejectedWiped, err = wipeRejected(tx, now.AddDate(0, 0, -policy.RejectedDays), &report.Staged); err != nil {
+				return err
+			}
+		}
+		if policy.PublishedDays > 0 {
+			if report.PublishedAnonymised, err = anonymisePublished(tx, now.AddDate(0, 0, -policy.PublishedDays), &report.Staged); err != nil {
+				return err
+			}
+		}
+		if policy.SenderDays > 0 {
+			if report.SendersForgotten, err = forgetSenders(tx, now.AddDate(0, 0, -policy.SenderDays)); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return report, err
+}
+
+// wipeRejected стирает текст, файлы и кнопки непубликовавшихся предложений
+func wipeRejected(tx *gorm.DB, cutoff time.Time, staged *[]StagedCopy) (int, error) {
+	ids, err := purgeTargets(tx.Model(&Message{}).
+		Where("status IN ? AND decided_at < ?", []string{"rejected", "withdrawn", "expired"}, cutoff).
+		Where("message_text <> '' OR media_file_id <> '' OR forward_source <> '' OR staging_message_id <> 0"), staged)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = tx.Model(&Message{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"message_text":       "",
+		"media_file_id":      "",
+		"forward_source":     "",
+		"staging_chat_id":    0,
+		"staging_message_id": 0,
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&MessagePart{}).Where("proposal_id IN ?", ids).Updates(map[string]interface{}{"text": "", "media_file_id": ""}).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("proposal_id IN ?", ids).Delete(&PostButton{}).Error; err != nil {
+		return 0, err
+	}
+	// В журнале правок хранится прежний текст поста
+	if err := tx.Model(&ActionLog{}).Where("proposal_id IN ?", ids).Update("details", "").Error; err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// anonymisePublished удаляет у опубликованных предложений всё, что связывает их с автором
+// и его сообщениями, и сохранённые ID файлов
+func anonymisePublished(tx *gorm.DB, cutoff time.Time, staged *[]StagedCopy) (int, error) {
+	ids, err := purgeTargets(tx.Model(&Message{}).
+		Where("status IN ? AND decided_at < ?", []string{"approved", "deleted"}, cutoff).
+		Where("sender_ref <> '' OR sender_seal <> '' OR media_file_id <> '' OR message_id <> 0 OR staging_message_id <> 0"), staged)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = tx.Model(&Message{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"sender_ref":         "",
+		"sender_seal":        "",
+		"media_file_id":      "",
+		"message_id":         0,
+		"staging_chat_id":    0,
+		"staging_message_id": 0,
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&MessagePart{}).Where("proposal_id IN ?", ids).Updates(map[string]interface{}{"media_file_id": "", "message_id": 0}).Error; err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purgeTargets возвращает ID предложений, выбранных запросом, и добавляет в staged
+// их копии в служебном чате
+func purgeTargets(query *gorm.DB, staged *[]StagedCopy) ([]uint, error) {
+	var targets []struct {
+		ID               uint
+		StagingChatID    int64
+		StagingMessageID int
+	}
+	if err := query.Select("id, staging_chat_id, staging_message_id").Scan(&targets).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(targets))
+	for _, target := range targets {
+		ids = append(ids, target.ID)
+		if target.StagingMessageID != 0 {
+			*staged = append(*staged, StagedCopy{ProposalID: target.ID, ChatID: target.StagingChatID, MessageID: target.StagingMessageID})
+		}
+	}
+	return ids, nil
+}
+
+// forgetSenders удаляет анонимные ссылки на отправителей рассмотренных предложений
+func forgetSenders(tx *gorm.DB, cutoff time.Time) (int, error) {
+	result := tx.Model(&Message{}).
+		Where("status NOT IN ? AND created_at < ?", []string{"pending", "scheduled"}, cutoff).
+		Where("sender_ref <> '' OR sender_seal <> ''").
+		Updates(map[string]interface{}{"sender_ref": "", "sender_seal": ""})
+	return int(result.RowsAffected), result.Error
+}
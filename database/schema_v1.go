@@ -0,0 +1,103 @@
+package database
+
+import "time"
+
+// Схема базы данных на момент появления миграций (миграция initial_schema). Типы заморожены:
+// первая миграция должна создавать одни и те же таблицы, как бы ни менялись модели потом.
+// Новые столбцы и таблицы добавляются только новыми миграциями.
+
+type v1Message struct {
+	ID               uint `gorm:"primaryKey"`
+	MessageID        int  `gorm:"not null"`
+	MessageText      string
+	MediaType        string `gorm:"size:50"`
+	MediaFileID      string
+	CreatedAt        time.Time
+	Status           string `gorm:"default:'pending'"`
+	ChannelID        int64
+	SenderRef        string `gorm:"index;size:64"`
+	SenderSeal       string
+	Ticket           string `gorm:"size:16"`
+	DecidedAt        *time.Time
+	ForwardSource    string `gorm:"size:255"`
+	StagingChatID    int64
+	StagingMessageID int
+	PublishAt        *time.Time
+	EditedAt         *time.Time
+	Parts            []v1MessagePart `gorm:"foreignKey:ProposalID"`
+	Buttons          []v1PostButton  `gorm:"foreignKey:ProposalID"`
+	Posts            []v1ChannelPost `gorm:"foreignKey:ProposalID"`
+}
+
+type v1MessagePart struct {
+	ID          uint `gorm:"primaryKey"`
+	ProposalID  uint `gorm:"index;not null"`
+	MessageID   int
+	Position    int
+	MediaType   string `gorm:"size:50"`
+	MediaFileID string
+	Text        string
+}
+
+type v1PostButton struct {
+	ID         uint `gorm:"primaryKey"`
+	ProposalID uint `gorm:"index;not null"`
+	Position   int
+	Text       string
+	URL        string
+}
+
+type v1ChannelPost struct {
+	ID          uint `gorm:"primaryKey"`
+	ProposalID  uint `gorm:"index;not null"`
+	Position    int
+	ChatID      int64
+	MessageID   int
+	TextKind    string `gorm:"size:16"`
+	HasKeyboard bool
+	Exact       bool
+}
+
+type v1ActionLog struct {
+	ID         uint   `gorm:"primaryKey"`
+	ProposalID uint   `gorm:"index;not null"`
+	ActorID    int64  `gorm:"index"`
+	Action     string `gorm:"size:32"`
+	Details    string
+	CreatedAt  time.Time
+}
+
+type v1Admin struct {
+	ID       uint  `gorm:"primaryKey"`
+	UserID   int64 `gorm:"uniqueIndex;not null"`
+	UserName string
+}
+
+type v1Setting struct {
+	Key   string `gorm:"primaryKey;size:100"`
+	Value string
+}
+
+type v1UserLanguage struct {
+	UserID int64  `gorm:"primaryKey;autoIncrement:false"`
+	Lang   string `gorm:"size:10"`
+}
+
+type v1NotifyPreference struct {
+	UserID        int64  `gorm:"primaryKey;autoIncrement:false"`
+	Mode          string `gorm:"size:16"`
+	DigestMinutes int
+	QuietFrom     int
+	QuietTo       int
+	NotifiedAt    *time.Time
+}
+
+func (v1Message) TableName() string          { return "messages" }
+func (v1MessagePart) TableName() string      { return "message_parts" }
+func (v1PostButton) TableName() string       { return "post_buttons" }
+func (v1ChannelPost) TableName() string      { return "channel_posts" }
+func (v1ActionLog) TableName() string        { return "action_logs" }
+func (v1Admin) TableName() string            { return "admins" }
+func (v1Setting) TableName() string          { return "settings" }
+func (v1UserLanguage) TableName() string     { return "user_languages" }
+func (v1NotifyPreference) TableName() string { return "notify_preferences" }
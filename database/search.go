@@ -0,0 +1,69 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SearchFilter - условия поиска предложений; пустые поля не ограничивают выборку
+type SearchFilter struct {
+	Query     string
+	Status    string
+	MediaType string
+	Since     time.Time
+	Until     time.Time
+}
+
+// SearchMessages ищет предложения по тексту и фильтрам, от новых к старым. В SQLite
+// используется индекс FTS5 (слова ищутся по префиксу), иначе - LIKE/ILIKE по подстроке.
+func (d *Database) SearchMessages(filter SearchFilter, limit, offset int) ([]Message, int64, error) {
+	query := d.db.Model(&Message{})
+
+	if text := strings.TrimSpace(filter.Query); text != "" {
+		switch {
+		case d.db.Dialector.Name() == "sqlite" && d.db.Migrator().HasTable("messages_fts"):
+			query = query.Where("id IN (SELECT rowid FROM messages_fts WHERE messages_fts MATCH ?)", ftsQuery(text))
+		case d.db.Dialector.Name() == "postgres":
+			query = query.Where("message_text ILIKE ?", "%"+escapeLike(text)+"%")
+		default:
+			query = query.Where("message_text LIKE ? ESCAPE '\\'", "%"+escapeLike(text)+"%")
+		}
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MediaType != "" {
+		query = query.Where("media_type = ?", filter.MediaType)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []Message
+	err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&messages).Error
+	return messages, total, err
+}
+
+// ftsQuery превращает введённый текст в запрос FTS5: каждое слово берётся в кавычки,
+// чтобы служебные символы не ломали запрос, и ищется по префиксу
+func ftsQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
@@ -0,0 +1,67 @@
+package database
+
+import "time"
+
+// Storage - хранилище бота, с которым работают обработчики. Реализуется Database поверх
+// SQLite, PostgreSQL или SQLite в памяти (NewMemoryDatabase).
+type Storage interface {
+	// Предложения
+	SaveMessage(msg *Message) error
+	GetMessageByID(id uint) (Message, error)
+	GetMessageByTicket(ticket string) (Message, error)
+	GetMessageBySource(senderRef string, messageID int) (Message, error)
+	GetMessagesBySender(senderRef string, limit int) ([]Message, error)
+	GetPendingMessages() ([]Message, error)
+	GetPendingOlderThan(cutoff time.Time) ([]Message, error)
+	GetOldestPending() (Message, bool, error)
+	CountPendingSince(since time.Time) (int64, error)
+	UpdateMessageStatus(id uint, from, to string) (bool, error)
+	ReturnToPending(id uint, from, senderSeal string) (bool, error)
+	UpdateMessageText(id uint, text string) error
+	UpdatePendingContent(msg *Message) (bool, error)
+	ClearStagedCopy(id uint) error
+	DeleteMessage(id uint) error
+	SearchMessages(filter SearchFilter, limit, offset int) ([]Message, int64, error)
+
+	// Публикация
+	ScheduleMessage(id uint, publishAt time.Time) (bool, error)
+	GetDueMessages(now time.Time) ([]Message, error)
+	SetPostButtons(proposalID uint, buttons []PostButton) (bool, error)
+	SaveChannelPosts(proposalID uint, posts []ChannelPost) error
+	DeleteChannelPosts(ids []uint) error
+
+	// Журнал действий и статистика
+	LogAction(proposalID uint, actorID int64, action, details string) error
+	GetActions(proposalID uint, limit int) ([]ActionLog, error)
+	GetDecisions(since time.Time) ([]Decision, error)
+	CountByStatus(since time.Time) ([]GroupCount, error)
+	CountByMediaType(since time.Time) ([]GroupCount, error)
+	CountByDay(since time.Time) ([]GroupCount, error)
+	CountByHour(since time.Time) ([]GroupCount, error)
+	AverageWait(since time.Time) (time.Duration, error)
+
+	// Администраторы и их настройки
+	IsAdmin(userID int64) bool
+	AddAdmin(userID int64, userName string) error
+	RemoveAdmin(userID int64) error
+	GetAdmins() ([]Admin, error)
+	GetNotifyPreference(userID int64) (NotifyPreference, bool, error)
+	SaveNotifyPreference(preference NotifyPreference) error
+	SetNotifiedAt(userID int64, notifiedAt time.Time) error
+
+	// Настройки бота и язык пользователей
+	GetSettings() ([]Setting, error)
+	SetSetting(key, value string) error
+	DeleteSetting(key string) error
+	GetUserLanguage(userID int64) (string, error)
+	SetUserLanguage(userID int64, lang string) error
+
+	// Хранение данных
+	Purge(policy RetentionPolicy, now time.Time, dryRun bool) (PurgeReport, error)
+
+	// Резервные копии
+	Export() (*Archive, error)
+	Import(archive *Archive) (ImportResult, error)
+}
+
+var _ Storage = (*Database)(nil)
@@ -0,0 +1,488 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// forEachBackend запускает тест на базе SQLite в памяти, на файле SQLite и, если задана
+// строка подключения TEST_POSTGRES_DSN (или DATABASE_URL с PostgreSQL), на PostgreSQL.
+// Тест в PostgreSQL работает в отдельной схеме, которая удаляется после него, поэтому
+// данные базы не трогаются.
+func forEachBackend(t *testing.T, test func(t *testing.T, db *Database)) {
+	t.Run("memory", func(t *testing.T) {
+		db, err := NewMemoryDatabase()
+		if err != nil {
+			t.Fatalf("NewMemoryDatabase: %v", err)
+		}
+		t.Cleanup(func() { closeDatabase(db) })
+		test(t, db)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := NewDatabase(filepath.Join(t.TempDir(), "bot.db"))
+		if err != nil {
+			t.Fatalf("NewDatabase: %v", err)
+		}
+		t.Cleanup(func() { closeDatabase(db) })
+		test(t, db)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := postgresDSN()
+		if dsn == "" {
+			t.Skip("TEST_POSTGRES_DSN не задан")
+		}
+		test(t, openPostgres(t, dsn))
+	})
+}
+
+func postgresDSN() string {
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		return dsn
+	}
+	if dsn := os.Getenv("DATABASE_URL"); dsn != "" && dialector(dsn).Name() == "postgres" {
+		return dsn
+	}
+	return ""
+}
+
+func openPostgres(t *testing.T, dsn string) *Database {
+	t.Helper()
+
+	admin, err := Connect(dsn)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.db.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("CREATE SCHEMA: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		closeDatabase(admin)
+	})
+
+	db, err := NewDatabase(withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(func() { closeDatabase(db) })
+	return db
+}
+
+// withSearchPath добавляет к строке подключения схему по умолчанию
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
+
+func closeDatabase(d *Database) {
+	if sqlDB, err := d.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+var proposalCounter atomic.Int64
+
+// saveProposal сохраняет ожидающее предложение с уникальным кодом отслеживания
+func saveProposal(t *testing.T, db *Database, text string, createdAt time.Time) *Message {
+	t.Helper()
+
+	n := proposalCounter.Add(1)
+	message := &Message{
+		MessageID:   int(n),
+		MessageText: text,
+		MediaType:   "text",
+		CreatedAt:   createdAt,
+		Status:      "pending",
+		SenderRef:   "sender",
+		Ticket:      fmt.Sprintf("T%d", n),
+	}
+	if err := db.SaveMessage(message); err != nil {
+		t.Fatalf("SaveMessage: %v", err)
+	}
+	return message
+}
+
+func TestMessageLifecycle(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "первое предложение", time.Now())
+
+		message, err := db.GetMessageByID(saved.ID)
+		if err != nil {
+			t.Fatalf("GetMessageByID: %v", err)
+		}
+		if message.MessageText != saved.MessageText || message.Status != "pending" {
+			t.Fatalf("GetMessageByID = %q (%s), ожидалось %q (pending)", message.MessageText, message.Status, saved.MessageText)
+		}
+
+		if byTicket, err := db.GetMessageByTicket(saved.Ticket); err != nil || byTicket.ID != saved.ID {
+			t.Fatalf("GetMessageByTicket = %d, %v; ожидалось %d", byTicket.ID, err, saved.ID)
+		}
+		if bySource, err := db.GetMessageBySource("sender", saved.MessageID); err != nil || bySource.ID != saved.ID {
+			t.Fatalf("GetMessageBySource = %d, %v; ожидалось %d", bySource.ID, err, saved.ID)
+		}
+		if _, err := db.GetMessageBySource("other", saved.MessageID); err == nil {
+			t.Fatal("GetMessageBySource нашёл предложение другого отправителя")
+		}
+
+		ok, err := db.UpdateMessageStatus(saved.ID, "pending", "approved")
+		if err != nil || !ok {
+			t.Fatalf("UpdateMessageStatus(pending -> approved) = %v, %v", ok, err)
+		}
+		if ok, _ := db.UpdateMessageStatus(saved.ID, "pending", "rejected"); ok {
+			t.Fatal("UpdateMessageStatus изменил уже рассмотренное предложение")
+		}
+
+		approved, _ := db.GetMessageByID(saved.ID)
+		if approved.DecidedAt == nil {
+			t.Fatal("у одобренного предложения нет времени решения")
+		}
+
+		// Удаление поста не меняет время решения
+		time.Sleep(10 * time.Millisecond)
+		if ok, err := db.UpdateMessageStatus(saved.ID, "approved", "deleted"); err != nil || !ok {
+			t.Fatalf("UpdateMessageStatus(approved -> deleted) = %v, %v", ok, err)
+		}
+		deleted, _ := db.GetMessageByID(saved.ID)
+		if deleted.DecidedAt == nil || !deleted.DecidedAt.Equal(*approved.DecidedAt) {
+			t.Fatalf("время решения изменилось: %v -> %v", approved.DecidedAt, deleted.DecidedAt)
+		}
+	})
+}
+
+func TestUpdateMessageStatusRace(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "спорное предложение", time.Now())
+
+		const moderators = 8
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		won := 0
+		for i := 0; i < moderators; i++ {
+			to := "approved"
+			if i%2 == 1 {
+				to = "rejected"
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := db.UpdateMessageStatus(saved.ID, "pending", to)
+				if err != nil {
+					t.Errorf("UpdateMessageStatus: %v", err)
+				}
+				if ok {
+					mu.Lock()
+					won++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if won != 1 {
+			t.Fatalf("решение приняли %d модераторов, ожидался один", won)
+		}
+	})
+}
+
+func TestReturnToPendingRestoresSeal(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "неопубликованное", time.Now())
+		saved.SenderSeal = "seal"
+		if err := db.db.Model(saved).Update("sender_seal", saved.SenderSeal).Error; err != nil {
+			t.Fatalf("запись seal: %v", err)
+		}
+
+		if ok, err := db.UpdateMessageStatus(saved.ID, "pending", "approved"); err != nil || !ok {
+			t.Fatalf("UpdateMessageStatus = %v, %v", ok, err)
+		}
+		if approved, _ := db.GetMessageByID(saved.ID); approved.SenderSeal != "" {
+			t.Fatal("одобрение не стёрло зашифрованный ID автора")
+		}
+
+		if ok, err := db.ReturnToPending(saved.ID, "approved", saved.SenderSeal); err != nil || !ok {
+			t.Fatalf("ReturnToPending = %v, %v", ok, err)
+		}
+		message, _ := db.GetMessageByID(saved.ID)
+		if message.Status != "pending" || message.SenderSeal != "seal" || message.DecidedAt != nil {
+			t.Fatalf("после возврата: статус %s, seal %q, решение %v", message.Status, message.SenderSeal, message.DecidedAt)
+		}
+
+		if ok, _ := db.ReturnToPending(saved.ID, "approved", saved.SenderSeal); ok {
+			t.Fatal("ReturnToPending изменил предложение в другом статусе")
+		}
+	})
+}
+
+func TestSetPostButtonsOnlyPending(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "пост с кнопками", time.Now())
+
+		buttons := []PostButton{{Text: "Сайт", URL: "https://example.com"}}
+		if ok, err := db.SetPostButtons(saved.ID, buttons); err != nil || !ok {
+			t.Fatalf("SetPostButtons = %v, %v", ok, err)
+		}
+
+		if _, err := db.UpdateMessageStatus(saved.ID, "pending", "approved"); err != nil {
+			t.Fatalf("UpdateMessageStatus: %v", err)
+		}
+		if ok, err := db.SetPostButtons(saved.ID, nil); err != nil || ok {
+			t.Fatalf("SetPostButtons после одобрения = %v, %v", ok, err)
+		}
+
+		message, _ := db.GetMessageByID(saved.ID)
+		if len(message.Buttons) != 1 {
+			t.Fatalf("у одобренного предложения %d кнопок, ожидалась одна", len(message.Buttons))
+		}
+	})
+}
+
+func TestSettingsUpsert(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		if err := db.SetSetting("undo_seconds", "10"); err != nil {
+			t.Fatalf("SetSetting: %v", err)
+		}
+		if err := db.SetSetting("undo_seconds", "30"); err != nil {
+			t.Fatalf("SetSetting (повторно): %v", err)
+		}
+
+		settings, err := db.GetSettings()
+		if err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+		if len(settings) != 1 || settings[0].Value != "30" {
+			t.Fatalf("GetSettings = %v, ожидалась одна настройка со значением 30", settings)
+		}
+	})
+}
+
+func TestSearchMessages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		now := time.Now()
+		// Текст в нижнем регистре: ILIKE в PostgreSQL с локалью C не сравнивает кириллицу без учёта регистра
+		cat := saveProposal(t, db, "кот спит на солнце", now.Add(-3*time.Hour))
+		saveProposal(t, db, "собака гуляет", now.Add(-2*time.Hour))
+		kitten := saveProposal(t, db, "котёнок и кот играют", now.Add(-time.Hour))
+		if _, err := db.UpdateMessageStatus(kitten.ID, "pending", "approved"); err != nil {
+			t.Fatalf("UpdateMessageStatus: %v", err)
+		}
+
+		messages, total, err := db.SearchMessages(SearchFilter{Query: "кот"}, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchMessages: %v", err)
+		}
+		if total != 2 || len(messages) != 2 || messages[0].ID != kitten.ID || messages[1].ID != cat.ID {
+			t.Fatalf("поиск «кот» вернул %d из %d, ожидались #%d и #%d", len(messages), total, kitten.ID, cat.ID)
+		}
+
+		messages, total, err = db.SearchMessages(SearchFilter{Query: "кот", Status: "pending"}, 10, 0)
+		if err != nil || total != 1 || messages[0].ID != cat.ID {
+			t.Fatalf("поиск с фильтром статуса = %d, %v", total, err)
+		}
+
+		_, total, err = db.SearchMessages(SearchFilter{Since: now.Add(-150 * time.Minute), Until: now}, 10, 0)
+		if err != nil || total != 2 {
+			t.Fatalf("поиск по датам нашёл %d, %v; ожидалось 2", total, err)
+		}
+
+		messages, total, err = db.SearchMessages(SearchFilter{MediaType: "text"}, 2, 2)
+		if err != nil || total != 3 || len(messages) != 1 || messages[0].ID != cat.ID {
+			t.Fatalf("вторая страница = %d из %d, %v", len(messages), total, err)
+		}
+	})
+}
+
+func TestDecisionsIgnoreUndoneApprovals(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "отменённое одобрение", time.Now())
+		for _, entry := range []struct {
+			actor  int64
+			action string
+		}{{1, "approve"}, {1, "undo"}, {2, "approve"}} {
+			if err := db.LogAction(saved.ID, entry.actor, entry.action, ""); err != nil {
+				t.Fatalf("LogAction: %v", err)
+			}
+		}
+
+		decisions, err := db.GetDecisions(time.Time{})
+		if err != nil {
+			t.Fatalf("GetDecisions: %v", err)
+		}
+		if len(decisions) != 1 || decisions[0].ActorID != 2 {
+			t.Fatalf("GetDecisions = %v, ожидалось одно одобрение модератора 2", decisions)
+		}
+	})
+}
+
+func TestAggregates(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		now := time.Now()
+		first := saveProposal(t, db, "вчерашнее", now.Add(-26*time.Hour))
+		saveProposal(t, db, "сегодняшнее", now)
+		if _, err := db.UpdateMessageStatus(first.ID, "pending", "rejected"); err != nil {
+			t.Fatalf("UpdateMessageStatus: %v", err)
+		}
+
+		statuses, err := db.CountByStatus(time.Time{})
+		if err != nil || len(statuses) != 2 {
+			t.Fatalf("CountByStatus = %v, %v", statuses, err)
+		}
+
+		days, err := db.CountByDay(time.Time{})
+		if err != nil || len(days) != 2 || days[0].Value >= days[1].Value {
+			t.Fatalf("CountByDay = %v, %v; ожидались два дня по порядку", days, err)
+		}
+
+		wait, err := db.AverageWait(time.Time{})
+		if err != nil || wait < 25*time.Hour || wait > 27*time.Hour {
+			t.Fatalf("AverageWait = %v, %v; ожидалось около 26 ч", wait, err)
+		}
+	})
+}
+
+func TestPurgeDryRun(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		saved := saveProposal(t, db, "отклонённый текст", time.Now().AddDate(0, 0, -40))
+		saved.StagingChatID, saved.StagingMessageID = -100, 42
+		if _, err := db.UpdatePendingContent(saved); err != nil {
+			t.Fatalf("UpdatePendingContent: %v", err)
+		}
+		if _, err := db.UpdateMessageStatus(saved.ID, "pending", "rejected"); err != nil {
+			t.Fatalf("UpdateMessageStatus: %v", err)
+		}
+
+		policy := RetentionPolicy{RejectedDays: 30}
+		later := time.Now().AddDate(0, 0, 31)
+
+		report, err := db.Purge(policy, later, true)
+		if err != nil {
+			t.Fatalf("Purge (пробная): %v", err)
+		}
+		if report.RejectedWiped != 1 || len(report.Staged) != 1 || report.Staged[0].MessageID != 42 {
+			t.Fatalf("пробная очистка = %+v", report)
+		}
+		if message, _ := db.GetMessageByID(saved.ID); message.MessageText == "" {
+			t.Fatal("пробная очистка изменила данные")
+		}
+
+		if _, err := db.Purge(policy, later, false); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		message, _ := db.GetMessageByID(saved.ID)
+		if message.MessageText != "" || message.StagingMessageID != 0 {
+			t.Fatalf("после очистки осталось %q, копия %d", message.MessageText, message.StagingMessageID)
+		}
+
+		if report, _ := db.Purge(policy, later, true); !report.Empty() {
+			t.Fatalf("повторная очистка не пуста: %+v", report)
+		}
+	})
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, source *Database) {
+		saved := saveProposal(t, source, "предложение для архива", time.Now())
+		if err := source.LogAction(saved.ID, 7, "approve", ""); err != nil {
+			t.Fatalf("LogAction: %v", err)
+		}
+		if err := source.AddAdmin(7, "moderator"); err != nil {
+			t.Fatalf("AddAdmin: %v", err)
+		}
+		if err := source.SetSetting(SenderSecretKey, "secret"); err != nil {
+			t.Fatalf("SetSetting: %v", err)
+		}
+
+		archive, err := source.Export()
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		var buffer bytes.Buffer
+		if err := archive.WriteJSON(&buffer); err != nil {
+			t.Fatalf("WriteJSON: %v", err)
+		}
+		archive, err = ReadArchive(&buffer)
+		if err != nil {
+			t.Fatalf("ReadArchive: %v", err)
+		}
+
+		target, err := NewMemoryDatabase()
+		if err != nil {
+			t.Fatalf("NewMemoryDatabase: %v", err)
+		}
+		defer closeDatabase(target)
+
+		result, err := target.Import(archive)
+		if err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+		if result.Proposals != 1 || result.Actions != 1 || result.Admins != 1 || result.Settings != 1 {
+			t.Fatalf("Import = %+v", result)
+		}
+		imported, err := target.GetMessageByTicket(saved.Ticket)
+		if err != nil || imported.MessageText != saved.MessageText {
+			t.Fatalf("импортированное предложение = %q, %v", imported.MessageText, err)
+		}
+
+		// Повторный импорт того же архива ничего не добавляет
+		result, err = target.Import(archive)
+		if err != nil || result.Proposals != 0 || result.Duplicates != 1 || result.Actions != 0 {
+			t.Fatalf("повторный Import = %+v, %v", result, err)
+		}
+
+		// Архив с другим секретом не смешивается с чужими предложениями
+		archive.Settings = []Setting{{Key: SenderSecretKey, Value: "other"}}
+		if _, err := target.Import(archive); err == nil {
+			t.Fatal("Import принял архив с другим секретом")
+		}
+	})
+}
+
+func TestMigrateRollback(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *Database) {
+		if applied, err := db.Migrate(); err != nil || applied != 0 {
+			t.Fatalf("Migrate на актуальной базе = %d, %v", applied, err)
+		}
+
+		last := migrations[len(migrations)-1]
+		rolledBack, err := db.Rollback()
+		if err != nil || rolledBack.Version != last.version {
+			t.Fatalf("Rollback = %d, %v; ожидалась %d", rolledBack.Version, err, last.version)
+		}
+
+		statuses, err := db.MigrationStatus()
+		if err != nil {
+			t.Fatalf("MigrationStatus: %v", err)
+		}
+		if status := statuses[len(statuses)-1]; status.AppliedAt != nil {
+			t.Fatalf("миграция %d осталась применённой", status.Version)
+		}
+
+		if applied, err := db.Migrate(); err != nil || applied != 1 {
+			t.Fatalf("Migrate после отката = %d, %v", applied, err)
+		}
+
+		// Поиск работает и после повторного применения миграции
+		saveProposal(t, db, "после миграции", time.Now())
+		if _, total, err := db.SearchMessages(SearchFilter{Query: "миграции"}, 10, 0); err != nil || total != 1 {
+			t.Fatalf("SearchMessages после миграции = %d, %v", total, err)
+		}
+	})
+}
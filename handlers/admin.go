@@ -2,22 +2,26 @@ package handlers
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
 type AdminHandler struct {
-	db      *database.Database
+	db      database.Storage
+	i18n    *i18n.Localizer
 	ownerID int64
 }
 
-func NewAdminHandler(db *database.Database, ownerID int64) *AdminHandler {
+func NewAdminHandler(db database.Storage, localizer *i18n.Localizer, ownerID int64) *AdminHandler {
 	return &AdminHandler{
 		db:      db,
+		i18n:    localizer,
 		ownerID: ownerID,
 	}
 }
@@ -31,11 +35,12 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if msg == nil {
 		return
 	}
+	tr := a.i18n.For(msg.From)
 
 	if !a.IsOwner(msg.From.ID) {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Только владелец бота может добавлять администраторов.",
+			tr.T("admin_owner_only_add"),
 		))
 		return
 	}
@@ -45,8 +50,7 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if len(args) < 10 {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"📝 Использование: /addadmin <ID_пользователя>\n\n"+
-				"Пример: /addadmin 123456789",
+			tr.T("admin_add_usage"),
 		))
 		return
 	}
@@ -56,8 +60,7 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if err != nil || targetUserID == 0 {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Неверный формат ID. Используйте: /addadmin <ID_пользователя>\n\n"+
-				"Пример: /addadmin 123456789",
+			tr.T("admin_add_bad_id"),
 		))
 		return
 	}
@@ -65,7 +68,7 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if targetUserID == a.ownerID {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Вы уже являетесь владельцем бота.",
+			tr.T("admin_add_is_owner"),
 		))
 		return
 	}
@@ -73,7 +76,7 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if a.db.IsAdmin(targetUserID) {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			fmt.Sprintf("❌ Пользователь с ID %d уже является администратором.", targetUserID),
+			tr.T("admin_add_exists", targetUserID),
 		))
 		return
 	}
@@ -84,7 +87,7 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	})
 	if err != nil {
 		userName = fmt.Sprintf("user_%d", targetUserID)
-		log.Printf("Не удалось получить информацию о пользователе %d: %v", targetUserID, err)
+		slog.Warn("Не удалось получить информацию о пользователе", logger.User(targetUserID), "error", err)
 	} else {
 		if user.Type == "private" {
 			userName = user.FirstName
@@ -100,36 +103,35 @@ func (a *AdminHandler) HandleAddAdminCommand(bot *telego.Bot, update telego.Upda
 	if err != nil {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Ошибка при добавлении администратора: "+err.Error(),
+			tr.T("admin_add_error", err.Error()),
 		))
 		return
 	}
 
-	successMsg := fmt.Sprintf("✅ Пользователь %s (ID: %d) добавлен как администратор!", userName, targetUserID)
+	successMsg := tr.T("admin_added", userName, targetUserID)
 	bot.SendMessage(tu.Message(
 		tu.ID(msg.Chat.ID),
 		successMsg,
 	))
 
-	log.Printf("Добавлен новый администратор: %s (ID: %d)", userName, targetUserID)
+	slog.Info("Добавлен новый администратор", logger.User(targetUserID))
 
-	notificationMsg := "🎉 Вы были добавлены как модератор бота-предложки!\n\n" +
-		"Используйте команду /start для доступа к панели модерации."
+	notificationMsg := a.i18n.ForUserID(targetUserID).T("admin_added_notification")
 
 	_, err = bot.SendMessage(tu.Message(
 		tu.ID(targetUserID),
 		notificationMsg,
 	))
 	if err != nil {
-		log.Printf("Не удалось отправить уведомление пользователю %d: %v", targetUserID, err)
+		slog.Warn("Не удалось отправить уведомление пользователю", logger.User(targetUserID), "error", err)
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"⚠️ Администратор добавлен, но не удалось отправить ему уведомление.",
+			tr.T("admin_notify_failed"),
 		))
 	} else {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"✅ Уведомление отправлено новому администратору.",
+			tr.T("admin_notify_sent"),
 		))
 	}
 }
@@ -139,11 +141,12 @@ func (a *AdminHandler) HandleListAdminsCommand(bot *telego.Bot, update telego.Up
 	if msg == nil {
 		return
 	}
+	tr := a.i18n.For(msg.From)
 
 	if !a.IsOwner(msg.From.ID) {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Только владелец бота может просматривать список администраторов.",
+			tr.T("admin_owner_only_list"),
 		))
 		return
 	}
@@ -152,7 +155,7 @@ func (a *AdminHandler) HandleListAdminsCommand(bot *telego.Bot, update telego.Up
 	if err != nil {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"❌ Ошибка при получении списка администраторов: "+err.Error(),
+			tr.T("admin_list_error", err.Error()),
 		))
 		return
 	}
@@ -160,13 +163,13 @@ func (a *AdminHandler) HandleListAdminsCommand(bot *telego.Bot, update telego.Up
 	if len(admins) == 0 {
 		bot.SendMessage(tu.Message(
 			tu.ID(msg.Chat.ID),
-			"📋 Список модераторов пуст.",
+			tr.T("admin_list_empty"),
 		))
 		return
 	}
 
-	adminList := "📋 Список модераторов:\n\n"
-	adminList += fmt.Sprintf("👑 Владелец: ID %d\n", a.ownerID)
+	adminList := tr.T("admin_list_header")
+	adminList += tr.T("admin_list_owner", a.ownerID)
 
 	for i, admin := range admins {
 		adminList += fmt.Sprintf("%d. @%s (ID: %d)\n", i+1, admin.UserName, admin.UserID)
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	buttonsInputPrefix = "buttons:"
+	maxPostButtons     = 8
+)
+
+// HandleButtonsCallback просит модератора прислать кнопки-ссылки для поста
+func (m *ModerationHandler) HandleButtonsCallback(bot *telego.Bot, chatID int64, proposalID uint, callback *telego.CallbackQuery) {
+	tr := m.i18n.For(&callback.From)
+
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_not_found")))
+		return
+	}
+	if message.Status != "pending" {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_already_decided")))
+		return
+	}
+
+	m.inputs.Wait(callback.From.ID, fmt.Sprintf("%s%d", buttonsInputPrefix, proposalID))
+	bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+
+	current := tr.T("settings_empty_value")
+	if len(message.Buttons) > 0 {
+		current = formatButtons(message.Buttons)
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(chatID),
+		tr.T("buttons_prompt", proposalID, current),
+	))
+}
+
+// HandleButtonsInput сохраняет присланные модератором кнопки и показывает пост заново
+func (m *ModerationHandler) HandleButtonsInput(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	action, ok := m.inputs.Take(msg.From.ID)
+	if !ok || (!m.db.IsAdmin(msg.From.ID) && msg.From.ID != m.ownerID) {
+		return
+	}
+	tr := m.i18n.For(msg.From)
+
+	var proposalID uint
+	if n, _ := fmt.Sscanf(action, buttonsInputPrefix+"%d", &proposalID); n != 1 {
+		return
+	}
+
+	var buttons []database.PostButton
+	if text := strings.TrimSpace(msg.Text); text != "-" {
+		var err error
+		buttons, err = parseButtons(text, tr)
+		if err != nil {
+			m.inputs.Wait(msg.From.ID, action)
+			bot.SendMessage(tu.Message(
+				tu.ID(msg.Chat.ID),
+				tr.T("buttons_invalid", err.Error()),
+			))
+			return
+		}
+	}
+
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil || message.Status != "pending" {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_already_decided"),
+		))
+		return
+	}
+
+	// Предложение могли одобрить, пока модератор набирал кнопки
+	updated, err := m.db.SetPostButtons(proposalID, buttons)
+	if err != nil {
+		slog.Error("Ошибка сохранения кнопок предложения", "proposal", proposalID, "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("buttons_error"),
+		))
+		return
+	}
+	if !updated {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_already_decided"),
+		))
+		return
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("buttons_saved", len(buttons)),
+	))
+
+	message.Buttons = buttons
+	m.SendMessageForModeration(bot, msg.Chat.ID, message, tr)
+}
+
+// parseButtons разбирает кнопки в формате «Текст | https://ссылка», по одной на строку
+func parseButtons(text string, tr i18n.Printer) ([]database.PostButton, error) {
+	var buttons []database.PostButton
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		label, link, found := strings.Cut(line, "|")
+		label, link = strings.TrimSpace(label), strings.TrimSpace(link)
+		if !found || label == "" || link == "" {
+			return nil, errors.New(tr.T("validation_button_format", line))
+		}
+
+		parsed, err := url.Parse(link)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http" && parsed.Scheme != "tg") {
+			return nil, errors.New(tr.T("validation_button_url", link))
+		}
+
+		buttons = append(buttons, database.PostButton{Text: label, URL: link})
+	}
+
+	if len(buttons) == 0 {
+		return nil, errors.New(tr.T("validation_empty"))
+	}
+	if len(buttons) > maxPostButtons {
+		return nil, errors.New(tr.T("validation_button_count", maxPostButtons))
+	}
+	return buttons, nil
+}
+
+func formatButtons(buttons []database.PostButton) string {
+	lines := make([]string, 0, len(buttons))
+	for _, button := range buttons {
+		lines = append(lines, button.Text+" | "+button.URL)
+	}
+	return strings.Join(lines, "\n")
+}
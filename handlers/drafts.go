@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// Draft - предложение, которое пользователь ещё не подтвердил. Черновики хранятся
+// только в памяти: до подтверждения в базу не попадает ничего.
+type Draft struct {
+	ID        int
+	UserID    int64
+	ChatID    int64
+	Lang      string
+	Message   database.Message
+	ControlID int
+	ExpiresAt time.Time
+	// Collect - черновик собирается из нескольких сообщений (режим "draft")
+	Collect bool
+	Parts   []database.MessagePart
+}
+
+// expired сообщает, что время жизни черновика истекло. Нулевое ExpiresAt - без ограничения.
+func (d *Draft) expired(now time.Time) bool {
+	return !d.ExpiresAt.IsZero() && now.After(d.ExpiresAt)
+}
+
+type DraftStore struct {
+	mu     sync.Mutex
+	nextID int
+	drafts map[int64]*Draft
+}
+
+func NewDraftStore() *DraftStore {
+	return &DraftStore{drafts: make(map[int64]*Draft)}
+}
+
+// Put сохраняет черновик пользователя и возвращает заменённый черновик, если он был
+func (s *DraftStore) Put(draft *Draft) *Draft {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	draft.ID = s.nextID
+
+	previous := s.drafts[draft.UserID]
+	s.drafts[draft.UserID] = draft
+	return previous
+}
+
+func (s *DraftStore) Get(userID int64) (*Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[userID]
+	if !ok || draft.expired(time.Now()) {
+		return nil, false
+	}
+	return draft, true
+}
+
+// Collect добавляет часть к собираемому черновику пользователя. Если такого черновика нет,
+// начинается новый из template, а прежний черновик другого режима возвращается в replaced.
+func (s *DraftStore) Collect(template *Draft, add func(draft *Draft) bool) (draft *Draft, replaced *Draft, added bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft = s.drafts[template.UserID]
+	if draft == nil || !draft.Collect {
+		replaced = draft
+		s.nextID++
+		template.ID = s.nextID
+		draft = template
+		s.drafts[template.UserID] = draft
+	}
+
+	return draft, replaced, add(draft)
+}
+
+// SetControl запоминает сообщение с кнопками черновика и возвращает предыдущее
+func (s *DraftStore) SetControl(draft *Draft, messageID int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := draft.ControlID
+	draft.ControlID = messageID
+	return previous
+}
+
+// Take забирает черновик, если его номер совпадает с draftID
+func (s *DraftStore) Take(userID int64, draftID int) (*Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[userID]
+	if !ok || draft.ID != draftID || draft.expired(time.Now()) {
+		return nil, false
+	}
+	delete(s.drafts, userID)
+	return draft, true
+}
+
+// Expired удаляет и возвращает просроченные черновики
+func (s *DraftStore) Expired(now time.Time) []*Draft {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*Draft
+	for userID, draft := range s.drafts {
+		if draft.expired(now) {
+			expired = append(expired, draft)
+			delete(s.drafts, userID)
+		}
+	}
+	return expired
+}
+
+// saveDraft показывает пользователю предложение в том виде, в котором оно будет опубликовано,
+// и ждёт подтверждения
+func (p *ProposalsHandler) saveDraft(bot *telego.Bot, msg *telego.Message, tr i18n.Printer, message *database.Message) {
+	draft := &Draft{
+		UserID:  msg.From.ID,
+		ChatID:  msg.Chat.ID,
+		Lang:    tr.Lang,
+		Message: *message,
+	}
+	if timeout := p.settings.Int(settings.KeyDraftTimeout); timeout > 0 {
+		draft.ExpiresAt = time.Now().Add(time.Duration(timeout) * time.Minute)
+	}
+
+	// Копия в служебном чате делается до предпросмотра, чтобы автор увидел точную копию,
+	// которая и попадёт в канал
+	p.renderer.Stage(bot, msg.Chat.ID, message)
+	draft.Message = *message
+
+	if previous := p.drafts.Put(draft); previous != nil {
+		p.discardDraft(bot, previous, tr.T("draft_replaced"))
+	}
+
+	if _, err := p.renderer.Publish(bot, msg.Chat.ID, *message); err != nil {
+		slog.Warn("Ошибка отправки предпросмотра", "error", err)
+	}
+
+	keyboard := tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_draft_send")).WithCallbackData(fmt.Sprintf("draft_send_%d", draft.ID)),
+		),
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_draft_edit")).WithCallbackData(fmt.Sprintf("draft_edit_%d", draft.ID)),
+			tu.InlineKeyboardButton(tr.T("btn_draft_cancel")).WithCallbackData(fmt.Sprintf("draft_cancel_%d", draft.ID)),
+		),
+	)
+
+	control, err := bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("draft_preview_hint"),
+	).WithReplyMarkup(keyboard))
+	if err != nil {
+		slog.Warn("Ошибка отправки черновика", "error", err)
+		return
+	}
+	p.drafts.SetControl(draft, control.MessageID)
+}
+
+// maxDraftParts ограничивает число сообщений в собираемом черновике: два полных альбома
+const maxDraftParts = 2 * maxAlbumSize
+
+// collectDraft добавляет сообщение в собираемый черновик: все сообщения до нажатия
+// «Отправить» (или до истечения времени) становятся одним предложением
+func (p *ProposalsHandler) collectDraft(bot *telego.Bot, msg *telego.Message, tr i18n.Printer, message *database.Message) {
+	part := database.MessagePart{
+		MessageID:   msg.MessageID,
+		MediaType:   message.MediaType,
+		MediaFileID: message.MediaFileID,
+		Text:        msg.Text + msg.Caption,
+	}
+	maxLength := p.settings.Int(settings.KeyMaxTextLength)
+	timeout := p.settings.Int(settings.KeyDraftTimeout)
+
+	template := &Draft{
+		UserID:  msg.From.ID,
+		ChatID:  msg.Chat.ID,
+		Lang:    tr.Lang,
+		Collect: true,
+	}
+
+	tooMany := false
+	draft, replaced, added := p.drafts.Collect(template, func(draft *Draft) bool {
+		if len(draft.Parts) >= maxDraftParts {
+			tooMany = true
+			return false
+		}
+
+		parts := append(append([]database.MessagePart(nil), draft.Parts...), part)
+
+		composed := *message
+		if len(draft.Parts) > 0 {
+			composed = composeParts(draft.Message, parts)
+			if composed.ForwardSource == "" {
+				composed.ForwardSource = message.ForwardSource
+			}
+		}
+		if maxLength > 0 && int64(len([]rune(composed.MessageText))) > maxLength {
+			return false
+		}
+
+		draft.Parts = parts
+		draft.Message = composed
+		if timeout > 0 {
+			draft.ExpiresAt = time.Now().Add(time.Duration(timeout) * time.Minute)
+		}
+		return true
+	})
+
+	if replaced != nil {
+		p.discardDraft(bot, replaced, tr.T("draft_replaced"))
+	}
+
+	if !added {
+		text := tr.T("proposal_too_long", maxLength)
+		if tooMany {
+			text = tr.T("draft_too_many_parts", maxDraftParts)
+		}
+		bot.SendMessage(tu.Message(tu.ID(msg.Chat.ID), text))
+		return
+	}
+
+	keyboard := tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_draft_send")).WithCallbackData(fmt.Sprintf("draft_send_%d", draft.ID)),
+			tu.InlineKeyboardButton(tr.T("btn_draft_preview")).WithCallbackData(fmt.Sprintf("draft_preview_%d", draft.ID)),
+		),
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_draft_cancel")).WithCallbackData(fmt.Sprintf("draft_cancel_%d", draft.ID)),
+		),
+	)
+
+	control, err := bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("draft_collecting", len(draft.Parts)),
+	).WithReplyMarkup(keyboard))
+	if err != nil {
+		slog.Warn("Ошибка отправки черновика", "error", err)
+		return
+	}
+
+	// Кнопки остаются только под последним сообщением черновика
+	if previous := p.drafts.SetControl(draft, control.MessageID); previous != 0 {
+		bot.DeleteMessage(tu.Delete(tu.ID(msg.Chat.ID), previous))
+	}
+}
+
+// composeParts собирает несколько сообщений в одно предложение: тексты объединяются,
+// а если вложений больше одного, предложение становится составным. Части сохраняются
+// в любом случае, чтобы автор мог исправить любое из сообщений.
+func composeParts(base database.Message, parts []database.MessagePart) database.Message {
+	var texts []string
+	var media []database.MessagePart
+	for i := range parts {
+		parts[i].Position = i
+		if parts[i].Text != "" {
+			texts = append(texts, parts[i].Text)
+		}
+		if parts[i].MediaFileID != "" {
+			media = append(media, parts[i])
+		}
+	}
+
+	message := base
+	message.MessageText = strings.Join(texts, "\n\n")
+	message.Parts = parts
+
+	switch {
+	case len(media) == 0:
+		message.MediaType = "text"
+		message.MediaFileID = ""
+	case len(media) == 1 && supportsCaption(media[0].MediaType):
+		message.MediaType = media[0].MediaType
+		message.MediaFileID = media[0].MediaFileID
+	default:
+		message.MediaType = "composite"
+		message.MediaFileID = ""
+	}
+
+	return message
+}
+
+func (p *ProposalsHandler) HandleDraftCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := p.i18n.For(&callback.From)
+	userID := callback.From.ID
+
+	var draftID int
+	switch {
+	case scanCallback(callback.Data, "draft_send_%d", &draftID):
+		draft, ok := p.drafts.Take(userID, draftID)
+		if !ok {
+			p.answerOutdatedDraft(bot, callback, tr)
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		p.closeDraft(bot, draft, tr.T("draft_sent"))
+
+		message := draft.Message
+		message.CreatedAt = time.Now()
+		p.submitProposal(bot, draft.ChatID, tr, &message)
+
+	case scanCallback(callback.Data, "draft_preview_%d", &draftID):
+		draft, ok := p.drafts.Get(userID)
+		if !ok || draft.ID != draftID {
+			p.answerOutdatedDraft(bot, callback, tr)
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		if _, err := p.renderer.Publish(bot, draft.ChatID, draft.Message); err != nil {
+			slog.Warn("Ошибка отправки предпросмотра", "error", err)
+		}
+
+	case scanCallback(callback.Data, "draft_edit_%d", &draftID):
+		if draft, ok := p.drafts.Get(userID); !ok || draft.ID != draftID {
+			p.answerOutdatedDraft(bot, callback, tr)
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		bot.SendMessage(tu.Message(
+			tu.ID(callback.Message.Chat.ID),
+			tr.T("draft_edit_hint"),
+		))
+
+	case scanCallback(callback.Data, "draft_cancel_%d", &draftID):
+		draft, ok := p.drafts.Take(userID, draftID)
+		if !ok {
+			p.answerOutdatedDraft(bot, callback, tr)
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("draft_cancelled")))
+		p.discardDraft(bot, draft, tr.T("draft_cancelled"))
+	}
+}
+
+// ExpireDrafts завершает черновики по истечении времени: неподтверждённые удаляются,
+// а собираемые из нескольких сообщений отправляются модераторам
+func (p *ProposalsHandler) ExpireDrafts(bot *telego.Bot) {
+	for _, draft := range p.drafts.Expired(time.Now()) {
+		tr := p.i18n.Lang(draft.Lang)
+		if !draft.Collect {
+			p.discardDraft(bot, draft, tr.T("draft_expired"))
+			continue
+		}
+
+		p.closeDraft(bot, draft, tr.T("draft_auto_sent"))
+		message := draft.Message
+		message.CreatedAt = time.Now()
+		p.submitProposal(bot, draft.ChatID, tr, &message)
+	}
+}
+
+func (p *ProposalsHandler) answerOutdatedDraft(bot *telego.Bot, callback *telego.CallbackQuery, tr i18n.Printer) {
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("draft_outdated")))
+
+	bot.EditMessageReplyMarkup(&telego.EditMessageReplyMarkupParams{
+		ChatID:    tu.ID(callback.Message.Chat.ID),
+		MessageID: callback.Message.MessageID,
+	})
+}
+
+// discardDraft закрывает черновик, который не будет отправлен, и удаляет его копию
+// из служебного чата
+func (p *ProposalsHandler) discardDraft(bot *telego.Bot, draft *Draft, text string) {
+	p.closeDraft(bot, draft, text)
+	p.renderer.Unstage(bot, draft.Message)
+}
+
+// closeDraft заменяет сообщение с кнопками черновика итоговым текстом
+func (p *ProposalsHandler) closeDraft(bot *telego.Bot, draft *Draft, text string) {
+	if draft.ControlID == 0 {
+		return
+	}
+
+	_, err := bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    tu.ID(draft.ChatID),
+		MessageID: draft.ControlID,
+		Text:      text,
+	})
+	if err != nil {
+		slog.Warn("Ошибка обновления черновика", "error", err)
+	}
+}
+
+func scanCallback(data, format string, id *int) bool {
+	n, _ := fmt.Sscanf(data, format, id)
+	return n == 1
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// HandleEditedMessage переносит правки автора в предложение, пока оно ожидает модерации.
+// Предложение ищется по анонимной ссылке на отправителя и ID исправленного сообщения.
+func (p *ProposalsHandler) HandleEditedMessage(bot *telego.Bot, update telego.Update) {
+	msg := update.EditedMessage
+	if msg == nil || msg.From == nil || msg.Chat.Type != "private" {
+		return
+	}
+
+	userID := msg.From.ID
+	if p.db.IsAdmin(userID) || userID == p.ownerID {
+		return
+	}
+
+	message, err := p.db.GetMessageBySource(p.anon.Ref(userID), msg.MessageID)
+	if err != nil {
+		// Сообщение не стало предложением (например, ещё лежит в черновике)
+		return
+	}
+	tr := p.i18n.For(msg.From)
+
+	if message.Status != "pending" {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_edit_locked", message.ID),
+		).WithReplyToMessageID(msg.MessageID))
+		return
+	}
+
+	edited := p.applyEdit(message, msg)
+
+	if maxLength := p.settings.Int(settings.KeyMaxTextLength); maxLength > 0 && int64(len([]rune(edited.MessageText))) > maxLength {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_too_long", maxLength),
+		))
+		return
+	}
+
+	now := time.Now()
+	edited.EditedAt = &now
+	p.renderer.Stage(bot, msg.Chat.ID, &edited)
+
+	ok, err := p.db.UpdatePendingContent(&edited)
+	if err != nil || !ok {
+		// Правка не сохранена: новая копия никому не нужна
+		p.renderer.Unstage(bot, edited)
+	}
+	if err != nil {
+		slog.Error("Ошибка сохранения правки предложения", "proposal", message.ID, "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_edit_error"),
+		))
+		return
+	}
+	if !ok {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("proposal_edit_locked", message.ID),
+		).WithReplyToMessageID(msg.MessageID))
+		return
+	}
+	// Копия содержимого до правки больше не понадобится
+	p.renderer.Unstage(bot, message)
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("proposal_edit_saved", message.ID),
+	).WithReplyToMessageID(msg.MessageID))
+
+	slog.Info("✏️ Предложение изменено автором", "proposal", message.ID)
+}
+
+// applyEdit возвращает предложение с содержимым исправленного сообщения. В предложении
+// из нескольких сообщений заменяется только соответствующая часть.
+func (p *ProposalsHandler) applyEdit(message database.Message, msg *telego.Message) database.Message {
+	mediaType, mediaFileID := p.media.GetMediaInfo(msg)
+
+	if len(message.Parts) == 0 {
+		message.MediaType = mediaType
+		message.MediaFileID = mediaFileID
+		message.MessageText = p.media.ExtractMessageText(msg)
+		return message
+	}
+
+	for i := range message.Parts {
+		if message.Parts[i].MessageID == msg.MessageID {
+			message.Parts[i].MediaType = mediaType
+			message.Parts[i].MediaFileID = mediaFileID
+			message.Parts[i].Text = msg.Text + msg.Caption
+		}
+	}
+	return composeParts(message, message.Parts)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// ExpirePending снимает с очереди предложения, ожидающие дольше pending_ttl_days, и при
+// включённой настройке expire_notify сообщает об этом авторам
+func (p *ProposalsHandler) ExpirePending(bot *telego.Bot) {
+	days := p.settings.Int(settings.KeyPendingTTLDays)
+	if days <= 0 {
+		return
+	}
+
+	messages, err := p.db.GetPendingOlderThan(time.Now().AddDate(0, 0, -int(days)))
+	if err != nil {
+		slog.Error("Ошибка получения устаревших предложений", "error", err)
+		return
+	}
+
+	for _, message := range messages {
+		ok, err := p.db.UpdateMessageStatus(message.ID, "pending", "expired")
+		if err != nil {
+			slog.Error("Ошибка снятия предложения с очереди", "proposal", message.ID, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := p.db.LogAction(message.ID, 0, "expire", ""); err != nil {
+			slog.Error("Ошибка записи в журнал действий", "proposal", message.ID, "error", err)
+		}
+		slog.Info("⌛ Предложение снято с очереди по истечении срока", "proposal", message.ID)
+		p.unstage(bot, message)
+
+		if message.SenderSeal != "" && p.settings.Bool(settings.KeyExpireNotify) {
+			p.notifyExpired(bot, message, days)
+		}
+	}
+}
+
+func (p *ProposalsHandler) notifyExpired(bot *telego.Bot, message database.Message, days int64) {
+	userID, err := p.anon.Open(message.SenderSeal)
+	if err != nil {
+		slog.Error("Ошибка расшифровки автора предложения", "proposal", message.ID, "error", err)
+		return
+	}
+
+	_, err = bot.SendMessage(tu.Message(
+		tu.ID(userID),
+		p.i18n.ForUserID(userID).T("proposal_expired", message.Ticket, days),
+	))
+	if err != nil {
+		slog.Warn("Ошибка уведомления автора предложения", "proposal", message.ID, "error", err)
+	}
+}
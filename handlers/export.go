@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// HandleExportCommand отправляет владельцу архив базы данных в формате JSON.
+// Архив уходит через Telegram, поэтому секрет анонимных ссылок и зашифрованные ID
+// авторов в него не попадают: полный архив выгружается командой export на сервере.
+func (a *AdminHandler) HandleExportCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := a.i18n.For(msg.From)
+
+	if !a.IsOwner(msg.From.ID) {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	archive, err := a.db.Export()
+	var buffer bytes.Buffer
+	if err == nil {
+		archive.Redact()
+		err = archive.WriteJSON(&buffer)
+	}
+	if err != nil {
+		slog.Error("Ошибка выгрузки базы данных", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("export_error"),
+		))
+		return
+	}
+
+	name := fmt.Sprintf("export-%s.json", time.Now().Format("20060102-150405"))
+	_, err = bot.SendDocument(tu.Document(
+		tu.ID(msg.Chat.ID),
+		tu.File(tu.NameReader(&buffer, name)),
+	).WithCaption(tr.T("export_caption", len(archive.Proposals), len(archive.Admins), len(archive.Settings))).
+		WithProtectContent())
+	if err != nil {
+		slog.Warn("Ошибка отправки архива", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("export_error"),
+		))
+	}
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"telegram-bot/anon"
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoapi"
+)
+
+const (
+	testToken       = "1234567890:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	testOwnerID     = 1
+	testModeratorID = 7
+	testAuthorID    = 42
+	testStagingChat = -1001
+	testChannelID   = -1002
+)
+
+// fakeTelegram подменяет Bot API: запоминает вызовы и отвечает успехом, если fail
+// не требует ошибки
+type fakeTelegram struct {
+	mu     sync.Mutex
+	calls  []apiCall
+	nextID int
+	fail   func(call apiCall) bool
+}
+
+type apiCall struct {
+	method string
+	params map[string]any
+}
+
+func (c apiCall) int(key string) int64 {
+	value, _ := c.params[key].(float64)
+	return int64(value)
+}
+
+func (f *fakeTelegram) Call(url string, data *telegoapi.RequestData) (*telegoapi.Response, error) {
+	call := apiCall{method: path.Base(url)}
+	if data != nil && data.Buffer != nil {
+		if err := json.Unmarshal(data.Buffer.Bytes(), &call.params); err != nil {
+			return nil, err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+
+	if f.fail != nil && f.fail(call) {
+		return &telegoapi.Response{Error: &telegoapi.Error{ErrorCode: 400, Description: "Bad Request"}}, nil
+	}
+
+	var result any = true
+	switch {
+	case call.method == "sendMediaGroup":
+		media, _ := call.params["media"].([]any)
+		messages := make([]any, 0, len(media))
+		for range media {
+			messages = append(messages, f.message(call))
+		}
+		result = messages
+	case strings.HasPrefix(call.method, "send") || strings.HasPrefix(call.method, "edit") || call.method == "copyMessage":
+		result = f.message(call)
+	case call.method == "getMe":
+		result = map[string]any{"id": 100, "is_bot": true, "first_name": "bot", "username": "test_bot"}
+	case call.method == "getChat":
+		result = map[string]any{"id": call.int("chat_id"), "type": "channel"}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &telegoapi.Response{Ok: true, Result: raw}, nil
+}
+
+func (f *fakeTelegram) message(call apiCall) map[string]any {
+	f.nextID++
+	return map[string]any{
+		"message_id": f.nextID,
+		"date":       0,
+		"chat":       map[string]any{"id": call.int("chat_id"), "type": "private"},
+	}
+}
+
+// find возвращает вызовы метода method
+func (f *fakeTelegram) find(method string) []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found []apiCall
+	for _, call := range f.calls {
+		if call.method == method {
+			found = append(found, call)
+		}
+	}
+	return found
+}
+
+// deleted сообщает, удалял ли бот сообщение messageID в чате chatID
+func (f *fakeTelegram) deleted(chatID int64, messageID int) bool {
+	for _, call := range f.find("deleteMessage") {
+		if call.int("chat_id") == chatID && call.int("message_id") == int64(messageID) {
+			return true
+		}
+	}
+	return false
+}
+
+// sentText сообщает, отправлял ли бот текст text
+func (f *fakeTelegram) sentText(text string) bool {
+	for _, call := range f.find("sendMessage") {
+		if call.params["text"] == text {
+			return true
+		}
+	}
+	return false
+}
+
+// testEnv - обработчики, собранные так же, как в bot.registerHandlers, поверх
+// хранилища database.Storage и поддельного Bot API
+type testEnv struct {
+	api        *fakeTelegram
+	bot        *telego.Bot
+	db         database.Storage
+	i18n       *i18n.Localizer
+	anon       *anon.Keeper
+	proposals  *ProposalsHandler
+	moderation *ModerationHandler
+	notifier   *Notifier
+	inputs     *InputWaiter
+}
+
+// newTestEnv собирает обработчики поверх хранилища, которое wrap может подменить
+// частично, например чтобы вернуть ошибку. Служебный чат включён.
+func newTestEnv(t *testing.T, wrap func(database.Storage) database.Storage) *testEnv {
+	t.Helper()
+
+	memory, err := database.NewMemoryDatabase()
+	if err != nil {
+		t.Fatalf("NewMemoryDatabase: %v", err)
+	}
+	var db database.Storage = memory
+	if wrap != nil {
+		db = wrap(db)
+	}
+	if err := db.AddAdmin(testModeratorID, "moderator"); err != nil {
+		t.Fatalf("AddAdmin: %v", err)
+	}
+
+	api := &fakeTelegram{}
+	bot, err := telego.NewBot(testToken, telego.WithAPICaller(api), telego.WithDiscardLogger())
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	store := settings.NewStore(db)
+	store.SetDefault(settings.KeyChannelID, fmt.Sprint(testChannelID))
+	if err := store.Set(settings.KeyStagingChatID, fmt.Sprint(testStagingChat)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	env := &testEnv{api: api, bot: bot, db: db, anon: anon.NewKeeper([]byte("secret"))}
+	env.i18n = i18n.NewLocalizer(db, store)
+	env.inputs = NewInputWaiter(env.i18n)
+	renderer := NewRenderer(store, env.i18n)
+	env.notifier = NewNotifier(db, store, env.i18n, testOwnerID)
+	env.proposals = NewProposalsHandler(db, store, env.i18n, NewMediaHandler(db, env.i18n), renderer, env.notifier, env.anon, testOwnerID)
+	env.moderation = NewModerationHandler(db, store, env.i18n, renderer, env.inputs, testOwnerID)
+	return env
+}
+
+func privateMessage(userID int64, messageID int, text string) *telego.Message {
+	return &telego.Message{
+		MessageID: messageID,
+		From:      &telego.User{ID: userID, FirstName: "user"},
+		Chat:      telego.Chat{ID: userID, Type: "private"},
+		Text:      text,
+	}
+}
+
+func callback(userID int64, data string) *telego.CallbackQuery {
+	return &telego.CallbackQuery{
+		ID:      "callback",
+		From:    telego.User{ID: userID, FirstName: "user"},
+		Message: privateMessage(userID, 1, ""),
+		Data:    data,
+	}
+}
+
+// submit отправляет предложение от автора и возвращает его из хранилища
+func (e *testEnv) submit(t *testing.T, messageID int, text string) database.Message {
+	t.Helper()
+
+	e.proposals.HandleUserProposal(e.bot, telego.Update{Message: privateMessage(testAuthorID, messageID, text)})
+
+	message, err := e.db.GetMessageBySource(e.anon.Ref(testAuthorID), messageID)
+	if err != nil {
+		t.Fatalf("предложение не сохранено: %v", err)
+	}
+	return message
+}
+
+func (e *testEnv) text(key string, args ...any) string {
+	return e.i18n.Default().T(key, args...)
+}
+
+func TestSubmitProposalStagesCopy(t *testing.T) {
+	env := newTestEnv(t, nil)
+
+	message := env.submit(t, 10, "котики")
+	if message.Status != "pending" || message.MessageText != "котики" {
+		t.Fatalf("сохранено %q (%s)", message.MessageText, message.Status)
+	}
+	if message.StagingChatID != testStagingChat || message.StagingMessageID == 0 {
+		t.Fatalf("копия в служебном чате = %d/%d", message.StagingChatID, message.StagingMessageID)
+	}
+	if copies := env.api.find("copyMessage"); len(copies) != 1 || copies[0].int("from_chat_id") != testAuthorID {
+		t.Fatalf("copyMessage = %v", copies)
+	}
+	if !env.api.sentText(env.text("proposal_accepted", message.Ticket, message.Ticket)) {
+		t.Fatal("автор не получил код отслеживания")
+	}
+}
+
+// saveFailingStorage не сохраняет предложения
+type saveFailingStorage struct {
+	database.Storage
+}
+
+func (saveFailingStorage) SaveMessage(*database.Message) error {
+	return errors.New("база недоступна")
+}
+
+func TestSubmitProposalSaveErrorDeletesCopy(t *testing.T) {
+	env := newTestEnv(t, func(db database.Storage) database.Storage { return saveFailingStorage{db} })
+
+	env.proposals.HandleUserProposal(env.bot, telego.Update{Message: privateMessage(testAuthorID, 10, "котики")})
+
+	if !env.api.sentText(env.text("proposal_save_error")) {
+		t.Fatal("автор не узнал об ошибке")
+	}
+	// Первое сообщение фальшивого API - копия в служебном чате
+	if !env.api.deleted(testStagingChat, 1) {
+		t.Fatal("копия несохранённого предложения осталась в служебном чате")
+	}
+}
+
+func TestWithdrawDeletesStagedCopy(t *testing.T) {
+	env := newTestEnv(t, nil)
+	message := env.submit(t, 10, "передумал")
+
+	env.proposals.HandleWithdrawCallback(env.bot, telego.Update{CallbackQuery: callback(testAuthorID, fmt.Sprintf("withdraw_%d", message.ID))})
+
+	withdrawn, _ := env.db.GetMessageByID(message.ID)
+	if withdrawn.Status != "withdrawn" {
+		t.Fatalf("статус после отзыва = %s", withdrawn.Status)
+	}
+	if !env.api.deleted(testStagingChat, message.StagingMessageID) || withdrawn.StagingMessageID != 0 {
+		t.Fatalf("копия отозванного предложения не удалена: %d", withdrawn.StagingMessageID)
+	}
+}
+
+func TestEditReplacesStagedCopy(t *testing.T) {
+	env := newTestEnv(t, nil)
+	message := env.submit(t, 10, "опечатка")
+
+	env.proposals.HandleEditedMessage(env.bot, telego.Update{EditedMessage: privateMessage(testAuthorID, 10, "исправлено")})
+
+	edited, _ := env.db.GetMessageByID(message.ID)
+	if edited.MessageText != "исправлено" {
+		t.Fatalf("текст после правки = %q", edited.MessageText)
+	}
+	if edited.StagingMessageID == 0 || edited.StagingMessageID == message.StagingMessageID {
+		t.Fatalf("копия не заменена: %d", edited.StagingMessageID)
+	}
+	if !env.api.deleted(testStagingChat, message.StagingMessageID) {
+		t.Fatal("копия до правки осталась в служебном чате")
+	}
+}
+
+func TestNotifyCallbackRejectsUnknownMode(t *testing.T) {
+	env := newTestEnv(t, nil)
+
+	env.notifier.HandleNotifyCallback(env.bot, telego.Update{CallbackQuery: callback(testModeratorID, "notify_bogus")})
+	if _, found, err := env.db.GetNotifyPreference(testModeratorID); err != nil || found {
+		t.Fatalf("сохранён неизвестный режим: %v, %v", found, err)
+	}
+
+	env.notifier.HandleNotifyCallback(env.bot, telego.Update{CallbackQuery: callback(testModeratorID, "notify_"+notifyMuted)})
+	if preference, _, _ := env.db.GetNotifyPreference(testModeratorID); preference.Mode != notifyMuted {
+		t.Fatalf("режим = %q, ожидался %q", preference.Mode, notifyMuted)
+	}
+}
+
+// staleStorage отдаёт предложения со статусом pending, как если бы их одобрили
+// сразу после чтения
+type staleStorage struct {
+	database.Storage
+}
+
+func (s staleStorage) GetMessageByID(id uint) (database.Message, error) {
+	message, err := s.Storage.GetMessageByID(id)
+	message.Status = "pending"
+	return message, err
+}
+
+func TestButtonsInputAfterApproval(t *testing.T) {
+	env := newTestEnv(t, func(db database.Storage) database.Storage { return staleStorage{db} })
+	message := env.submit(t, 10, "пост со ссылкой")
+	if ok, err := env.db.UpdateMessageStatus(message.ID, "pending", "approved"); err != nil || !ok {
+		t.Fatalf("UpdateMessageStatus = %v, %v", ok, err)
+	}
+
+	env.inputs.Wait(testModeratorID, fmt.Sprintf("%s%d", buttonsInputPrefix, message.ID))
+	env.moderation.HandleButtonsInput(env.bot, telego.Update{Message: privateMessage(testModeratorID, 20, "Сайт | https://example.com")})
+
+	if !env.api.sentText(env.text("proposal_already_decided")) {
+		t.Fatal("модератор не узнал, что кнопки не сохранены")
+	}
+	if approved, _ := env.db.GetMessageByID(message.ID); len(approved.Buttons) != 0 {
+		t.Fatalf("у одобренного предложения записаны кнопки: %v", approved.Buttons)
+	}
+}
+
+func TestDeletePostKeepsUndeletedMessages(t *testing.T) {
+	env := newTestEnv(t, nil)
+	message := env.submit(t, 10, "длинный пост")
+	if ok, err := env.db.UpdateMessageStatus(message.ID, "pending", "approved"); err != nil || !ok {
+		t.Fatalf("UpdateMessageStatus = %v, %v", ok, err)
+	}
+	posts := []database.ChannelPost{
+		{ChatID: testChannelID, MessageID: 500},
+		{ChatID: testChannelID, MessageID: 501, TextKind: postText},
+	}
+	if err := env.db.SaveChannelPosts(message.ID, posts); err != nil {
+		t.Fatalf("SaveChannelPosts: %v", err)
+	}
+
+	// Второе сообщение старше 48 часов, Telegram его не удаляет
+	env.api.fail = func(call apiCall) bool {
+		return call.method == "deleteMessage" && call.int("message_id") == 501
+	}
+	data := fmt.Sprintf("post_delete_confirm_%d", message.ID)
+	env.moderation.HandlePostCallback(env.bot, telego.Update{CallbackQuery: callback(testModeratorID, data)})
+
+	kept, _ := env.db.GetMessageByID(message.ID)
+	if kept.Status != "approved" {
+		t.Fatalf("статус после неудачного удаления = %s", kept.Status)
+	}
+	if len(kept.Posts) != 1 || kept.Posts[0].MessageID != 501 {
+		t.Fatalf("сообщения поста = %v, ожидалось только 501", kept.Posts)
+	}
+
+	// Повторное нажатие после удаления поста другим модератором
+	env.api.fail = nil
+	if ok, _ := env.db.UpdateMessageStatus(message.ID, "approved", "deleted"); !ok {
+		t.Fatal("UpdateMessageStatus не удалил пост")
+	}
+	env.moderation.HandlePostCallback(env.bot, telego.Update{CallbackQuery: callback(testModeratorID, data)})
+	answers := env.api.find("answerCallbackQuery")
+	if last := answers[len(answers)-1]; last.params["text"] != env.text("proposal_already_decided") {
+		t.Fatalf("ответ на повторное удаление = %v", last.params["text"])
+	}
+}
+
+func TestSplitAlbum(t *testing.T) {
+	for total, want := range map[int][]int{
+		1:  {1},
+		10: {10},
+		11: {6, 5},
+		20: {10, 10},
+		21: {7, 7, 7},
+	} {
+		album := make([]telego.InputMedia, total)
+		var sizes []int
+		for _, group := range splitAlbum(album) {
+			sizes = append(sizes, len(group))
+		}
+		if fmt.Sprint(sizes) != fmt.Sprint(want) {
+			t.Errorf("splitAlbum(%d) = %v, ожидалось %v", total, sizes, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strings"
+	"sync"
+
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	th "github.com/mymmrac/telego/telegohandler"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// InputWaiter запоминает, от каких пользователей бот ждёт ответное сообщение
+type InputWaiter struct {
+	i18n    *i18n.Localizer
+	mu      sync.Mutex
+	pending map[int64]string
+}
+
+func NewInputWaiter(localizer *i18n.Localizer) *InputWaiter {
+	return &InputWaiter{
+		i18n:    localizer,
+		pending: make(map[int64]string),
+	}
+}
+
+// Wait ожидает от пользователя следующее сообщение для указанного действия
+func (w *InputWaiter) Wait(userID int64, action string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[userID] = action
+}
+
+// Take возвращает ожидаемое действие и снимает ожидание
+func (w *InputWaiter) Take(userID int64) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	action, ok := w.pending[userID]
+	delete(w.pending, userID)
+	return action, ok
+}
+
+func (w *InputWaiter) Cancel(userID int64) bool {
+	_, ok := w.Take(userID)
+	return ok
+}
+
+// Waiting срабатывает на сообщения в ЛС от пользователей, от которых ожидается ввод с данным префиксом
+func (w *InputWaiter) Waiting(prefix string) th.Predicate {
+	return func(update telego.Update) bool {
+		msg := update.Message
+		if msg == nil || msg.From == nil || msg.Chat.Type != "private" {
+			return false
+		}
+		if strings.HasPrefix(msg.Text, "/") {
+			return false
+		}
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		action, ok := w.pending[msg.From.ID]
+		return ok && strings.HasPrefix(action, prefix)
+	}
+}
+
+func (w *InputWaiter) HandleCancelCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	tr := w.i18n.For(msg.From)
+	text := tr.T("cancel_nothing")
+	if w.Cancel(msg.From.ID) {
+		text = tr.T("cancel_done")
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		text,
+	))
+}
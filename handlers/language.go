@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log/slog"
+	"strings"
+
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+type LanguageHandler struct {
+	i18n *i18n.Localizer
+}
+
+func NewLanguageHandler(localizer *i18n.Localizer) *LanguageHandler {
+	return &LanguageHandler{i18n: localizer}
+}
+
+func (l *LanguageHandler) HandleLanguageCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := l.i18n.For(msg.From)
+
+	var row []telego.InlineKeyboardButton
+	for _, lang := range i18n.Languages() {
+		row = append(row, tu.InlineKeyboardButton(i18n.LanguageName(lang)).WithCallbackData("lang_"+lang))
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("language_choose"),
+	).WithReplyMarkup(tu.InlineKeyboard(row)))
+}
+
+func (l *LanguageHandler) HandleCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+
+	lang := strings.TrimPrefix(callback.Data, "lang_")
+	if err := l.i18n.SetUserLang(callback.From.ID, lang); err != nil {
+		slog.Error("Ошибка сохранения языка пользователя", logger.User(callback.From.ID), "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(l.i18n.For(&callback.From).T("language_error")))
+		return
+	}
+
+	tr := l.i18n.For(&callback.From)
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("language_set")))
+
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    tu.ID(callback.Message.Chat.ID),
+		MessageID: callback.Message.MessageID,
+		Text:      tr.T("language_set"),
+	})
+}
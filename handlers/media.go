@@ -2,20 +2,23 @@ package handlers
 
 import (
 	"fmt"
-	"log"
+	"strings"
 
 	"telegram-bot/database"
+	"telegram-bot/i18n"
 
 	"github.com/mymmrac/telego"
 )
 
-// MediaHandler обрабатывает медиафайлы
+// MediaHandler разбирает входящие сообщения: тип медиа, текст и источник пересылки.
+// Отправкой постов занимается Renderer.
 type MediaHandler struct {
-	db *database.Database
+	db   database.Storage
+	i18n *i18n.Localizer
 }
 
-func NewMediaHandler(db *database.Database) *MediaHandler {
-	return &MediaHandler{db: db}
+func NewMediaHandler(db database.Storage, localizer *i18n.Localizer) *MediaHandler {
+	return &MediaHandler{db: db, i18n: localizer}
 }
 
 // GetMediaInfo определяет тип медиа и file_id
@@ -44,7 +47,32 @@ func (m *MediaHandler) GetMediaInfo(msg *telego.Message) (string, string) {
 	return "text", ""
 }
 
-// ExtractMessageText извлекает текст из сообщения
+// ForwardSource возвращает источник пересланного сообщения или пустую строку
+func (m *MediaHandler) ForwardSource(msg *telego.Message) string {
+	switch {
+	case msg.ForwardFromChat != nil:
+		return describeSource(msg.ForwardFromChat.Title, msg.ForwardFromChat.Username)
+	case msg.ForwardFrom != nil:
+		name := strings.TrimSpace(msg.ForwardFrom.FirstName + " " + msg.ForwardFrom.LastName)
+		return describeSource(name, msg.ForwardFrom.Username)
+	case msg.ForwardSenderName != "":
+		return msg.ForwardSenderName
+	}
+	return ""
+}
+
+func describeSource(name, username string) string {
+	if username == "" {
+		return name
+	}
+	if name == "" {
+		return "@" + username
+	}
+	return fmt.Sprintf("%s (@%s)", name, username)
+}
+
+// ExtractMessageText извлекает текст из сообщения. Описания медиа без подписи
+// публикуются как подпись, поэтому они формируются на языке канала.
 func (m *MediaHandler) ExtractMessageText(msg *telego.Message) string {
 	if msg.Text != "" {
 		return msg.Text
@@ -53,151 +81,28 @@ func (m *MediaHandler) ExtractMessageText(msg *telego.Message) string {
 		return msg.Caption
 	}
 
+	tr := m.i18n.Channel()
+
 	switch {
 	case msg.Photo != nil:
-		return "🖼️ Фото"
+		return tr.T("media_photo")
 	case msg.Document != nil:
-		return "📄 Документ: " + msg.Document.FileName
+		return tr.T("media_document", msg.Document.FileName)
 	case msg.Video != nil:
-		return "🎥 Видео"
+		return tr.T("media_video")
 	case msg.VideoNote != nil:
-		return "📹 Кружочек (видеосообщение)"
+		return tr.T("media_video_note")
 	case msg.Audio != nil:
-		title := "Аудио"
+		title := tr.T("media_audio_default")
 		if msg.Audio.Title != "" {
 			title = msg.Audio.Title
 		}
-		return "🎵 " + title
+		return tr.T("media_audio", title)
 	case msg.Voice != nil:
-		return "🎤 Голосовое сообщение"
+		return tr.T("media_voice")
 	case msg.Sticker != nil:
-		return "😊 Стикер"
+		return tr.T("media_sticker")
 	default:
-		return "📦 Медиа-контент"
+		return tr.T("media_other")
 	}
 }
-
-// SendMediaForModeration отправляет медиафайл для модерации
-func (m *MediaHandler) SendMediaForModeration(bot *telego.Bot, chatID int64, message database.Message) error {
-	if message.MediaType != "text" && message.MediaFileID != "" {
-		var sendErr error
-
-		switch message.MediaType {
-		case "photo":
-			_, sendErr = bot.SendPhoto(&telego.SendPhotoParams{
-				ChatID:  telego.ChatID{ID: chatID},
-				Photo:   telego.InputFile{FileID: message.MediaFileID},
-				Caption: message.MessageText,
-			})
-		case "document":
-			_, sendErr = bot.SendDocument(&telego.SendDocumentParams{
-				ChatID:   telego.ChatID{ID: chatID},
-				Document: telego.InputFile{FileID: message.MediaFileID},
-				Caption:  message.MessageText,
-			})
-		case "video":
-			_, sendErr = bot.SendVideo(&telego.SendVideoParams{
-				ChatID:  telego.ChatID{ID: chatID},
-				Video:   telego.InputFile{FileID: message.MediaFileID},
-				Caption: message.MessageText,
-			})
-		case "video_note":
-			_, sendErr = bot.SendVideoNote(&telego.SendVideoNoteParams{
-				ChatID:    telego.ChatID{ID: chatID},
-				VideoNote: telego.InputFile{FileID: message.MediaFileID},
-			})
-		case "audio":
-			_, sendErr = bot.SendAudio(&telego.SendAudioParams{
-				ChatID:  telego.ChatID{ID: chatID},
-				Audio:   telego.InputFile{FileID: message.MediaFileID},
-				Caption: message.MessageText,
-			})
-		case "voice":
-			_, sendErr = bot.SendVoice(&telego.SendVoiceParams{
-				ChatID:  telego.ChatID{ID: chatID},
-				Voice:   telego.InputFile{FileID: message.MediaFileID},
-				Caption: message.MessageText,
-			})
-		case "sticker":
-			_, sendErr = bot.SendSticker(&telego.SendStickerParams{
-				ChatID:  telego.ChatID{ID: chatID},
-				Sticker: telego.InputFile{FileID: message.MediaFileID},
-			})
-		}
-
-		if sendErr != nil {
-			log.Printf("Ошибка отправки медиа для модерации: %v", sendErr)
-			// Если не удалось отправить медиа, отправляем текстовое описание
-			_, err := bot.SendMessage(&telego.SendMessageParams{
-				ChatID: telego.ChatID{ID: chatID},
-				Text:   fmt.Sprintf("❌ Не удалось отобразить медиафайл (тип: %s)\n💬 Описание: %s", message.MediaType, message.MessageText),
-			})
-			return err
-		}
-	} else {
-		// Для текстовых сообщений просто отправляем текст
-		_, err := bot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: chatID},
-			Text:   fmt.Sprintf("💬 Текст предложения:\n%s", message.MessageText),
-		})
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func (m *MediaHandler) PublishMedia(bot *telego.Bot, channelID int64, message database.Message) error {
-	var sendErr error
-
-	switch message.MediaType {
-	case "photo":
-		_, sendErr = bot.SendPhoto(&telego.SendPhotoParams{
-			ChatID:  telego.ChatID{ID: channelID},
-			Photo:   telego.InputFile{FileID: message.MediaFileID},
-			Caption: message.MessageText,
-		})
-	case "document":
-		_, sendErr = bot.SendDocument(&telego.SendDocumentParams{
-			ChatID:   telego.ChatID{ID: channelID},
-			Document: telego.InputFile{FileID: message.MediaFileID},
-			Caption:  message.MessageText,
-		})
-	case "video":
-		_, sendErr = bot.SendVideo(&telego.SendVideoParams{
-			ChatID:  telego.ChatID{ID: channelID},
-			Video:   telego.InputFile{FileID: message.MediaFileID},
-			Caption: message.MessageText,
-		})
-	case "video_note":
-		_, sendErr = bot.SendVideoNote(&telego.SendVideoNoteParams{
-			ChatID:    telego.ChatID{ID: channelID},
-			VideoNote: telego.InputFile{FileID: message.MediaFileID},
-		})
-	case "audio":
-		_, sendErr = bot.SendAudio(&telego.SendAudioParams{
-			ChatID:  telego.ChatID{ID: channelID},
-			Audio:   telego.InputFile{FileID: message.MediaFileID},
-			Caption: message.MessageText,
-		})
-	case "voice":
-		_, sendErr = bot.SendVoice(&telego.SendVoiceParams{
-			ChatID:  telego.ChatID{ID: channelID},
-			Voice:   telego.InputFile{FileID: message.MediaFileID},
-			Caption: message.MessageText,
-		})
-	case "sticker":
-		_, sendErr = bot.SendSticker(&telego.SendStickerParams{
-			ChatID:  telego.ChatID{ID: channelID},
-			Sticker: telego.InputFile{FileID: message.MediaFileID},
-		})
-	default: // text
-		_, sendErr = bot.SendMessage(&telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: channelID},
-			Text:   fmt.Sprintf("💡 Новое предложение:\n\n%s", message.MessageText),
-		})
-	}
-
-	return sendErr
-}
@@ -2,27 +2,41 @@ package handlers
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
+	"time"
 
 	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
+	"telegram-bot/settings"
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
 type ModerationHandler struct {
-	db        *database.Database
-	media     *MediaHandler
-	channelID int64
-	ownerID   int64
+	db       database.Storage
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	renderer *Renderer
+	inputs   *InputWaiter
+	ownerID  int64
+
+	// searchMu защищает searches - последний поисковый запрос каждого модератора
+	searchMu sync.Mutex
+	searches map[int64]database.SearchFilter
 }
 
-func NewModerationHandler(db *database.Database, media *MediaHandler, channelID, ownerID int64) *ModerationHandler {
+func NewModerationHandler(db database.Storage, store *settings.Store, localizer *i18n.Localizer, renderer *Renderer, inputs *InputWaiter, ownerID int64) *ModerationHandler {
 	return &ModerationHandler{
-		db:        db,
-		media:     media,
-		channelID: channelID,
-		ownerID:   ownerID,
+		db:       db,
+		settings: store,
+		i18n:     localizer,
+		renderer: renderer,
+		inputs:   inputs,
+		ownerID:  ownerID,
+		searches: make(map[int64]database.SearchFilter),
 	}
 }
 
@@ -31,14 +45,15 @@ func (m *ModerationHandler) HandleProposalsCommand(bot *telego.Bot, update teleg
 	if msg == nil {
 		return
 	}
-	m.ShowProposals(bot, msg.Chat.ID, msg.From.ID)
+	m.ShowProposals(bot, msg.Chat.ID, msg.From)
 }
 
-func (m *ModerationHandler) ShowProposals(bot *telego.Bot, chatID int64, userID int64) {
-	if !m.db.IsAdmin(userID) && userID != m.ownerID {
+func (m *ModerationHandler) ShowProposals(bot *telego.Bot, chatID int64, user *telego.User) {
+	tr := m.i18n.For(user)
+	if !m.db.IsAdmin(user.ID) && user.ID != m.ownerID {
 		bot.SendMessage(tu.Message(
 			tu.ID(chatID),
-			"❌ У вас нет доступа к этой функции.",
+			tr.T("no_access"),
 		))
 		return
 	}
@@ -47,7 +62,7 @@ func (m *ModerationHandler) ShowProposals(bot *telego.Bot, chatID int64, userID
 	if err != nil {
 		bot.SendMessage(tu.Message(
 			tu.ID(chatID),
-			"❌ Ошибка при получении предложений: "+err.Error(),
+			tr.T("proposals_error", err.Error()),
 		))
 		return
 	}
@@ -55,44 +70,53 @@ func (m *ModerationHandler) ShowProposals(bot *telego.Bot, chatID int64, userID
 	if len(messages) == 0 {
 		bot.SendMessage(tu.Message(
 			tu.ID(chatID),
-			"✅ Нет новых предложений для модерации.",
+			tr.T("proposals_empty"),
 		))
 		return
 	}
 
 	bot.SendMessage(tu.Message(
 		tu.ID(chatID),
-		fmt.Sprintf("📨 Найдено %d предложений для модерации:", len(messages)),
+		tr.T("proposals_found", len(messages))+"\n"+tr.T("proposals_oldest", formatAge(tr, time.Since(messages[0].CreatedAt))),
 	))
 
-	m.SendMessageForModeration(bot, chatID, messages[0])
+	m.SendMessageForModeration(bot, chatID, messages[0], tr)
 }
 
-func (m *ModerationHandler) SendMessageForModeration(bot *telego.Bot, chatID int64, message database.Message) {
+func (m *ModerationHandler) SendMessageForModeration(bot *telego.Bot, chatID int64, message database.Message, tr i18n.Printer) {
 
-	if err := m.media.SendMediaForModeration(bot, chatID, message); err != nil {
-		log.Printf("Ошибка при отправке медиа для модерации: %v", err)
+	previewID, err := m.renderer.Preview(bot, chatID, message, tr)
+	if err != nil {
+		slog.Warn("Ошибка при отправке медиа для модерации", "proposal", message.ID, "error", err)
 	}
 
-	text := fmt.Sprintf(
-		"📨 Анонимное предложение #%d\n\n"+
-			"⏰ Время: %s\n\n"+
-			"Выберите действие:",
-		message.MessageID,
+	text := tr.T(
+		"moderation_card",
+		message.ID,
 		message.CreatedAt.Format("02.01.2006 15:04"),
 	)
+	if message.ForwardSource != "" {
+		text = tr.T("moderation_card_forwarded", message.ForwardSource) + "\n" + text
+	}
+	if message.EditedAt != nil {
+		text = tr.T("moderation_card_edited", message.EditedAt.Format("02.01.2006 15:04")) + "\n\n" + text
+	}
 
 	keyboard := tu.InlineKeyboard(
 		tu.InlineKeyboardRow(
-			tu.InlineKeyboardButton("✅ ОДОБРИТЬ").WithCallbackData(fmt.Sprintf("approve_%d", message.MessageID)),
-			tu.InlineKeyboardButton("❌ ОТКЛОНИТЬ").WithCallbackData(fmt.Sprintf("reject_%d", message.MessageID)),
+			tu.InlineKeyboardButton(tr.T("btn_approve")).WithCallbackData(fmt.Sprintf("approve_%d", message.ID)),
+			tu.InlineKeyboardButton(tr.T("btn_reject")).WithCallbackData(fmt.Sprintf("reject_%d", message.ID)),
+		),
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_post_buttons", len(message.Buttons))).WithCallbackData(fmt.Sprintf("buttons_%d", message.ID)),
 		),
 	)
 
+	// Карточка отвечает на предпросмотр, чтобы было видно, к какому посту она относится
 	bot.SendMessage(tu.Message(
 		tu.ID(chatID),
 		text,
-	).WithReplyMarkup(keyboard))
+	).WithReplyMarkup(keyboard).WithReplyToMessageID(previewID).WithAllowSendingWithoutReply())
 }
 
 func (m *ModerationHandler) HandleCallback(bot *telego.Bot, update telego.Update) {
@@ -107,72 +131,169 @@ func (m *ModerationHandler) HandleCallback(bot *telego.Bot, update telego.Update
 	if !m.db.IsAdmin(userID) && userID != m.ownerID {
 		bot.AnswerCallbackQuery(tu.CallbackQuery(
 			callback.ID,
-		).WithText("❌ У вас нет доступа."))
+		).WithText(m.i18n.For(&callback.From).T("callback_no_access")))
 		return
 	}
 
 	data := callback.Data
-	var messageID int
-
-	if n, _ := fmt.Sscanf(data, "approve_%d", &messageID); n == 1 {
-		m.HandleApprove(bot, chatID, messageID, callback)
-	} else if n, _ := fmt.Sscanf(data, "reject_%d", &messageID); n == 1 {
-		m.HandleReject(bot, chatID, messageID, callback)
+	var proposalID uint
+
+	if n, _ := fmt.Sscanf(data, "approve_%d", &proposalID); n == 1 {
+		m.HandleApprove(bot, chatID, proposalID, callback)
+	} else if n, _ := fmt.Sscanf(data, "reject_%d", &proposalID); n == 1 {
+		m.HandleReject(bot, chatID, proposalID, callback)
+	} else if n, _ := fmt.Sscanf(data, "buttons_%d", &proposalID); n == 1 {
+		m.HandleButtonsCallback(bot, chatID, proposalID, callback)
+	} else if n, _ := fmt.Sscanf(data, "undo_%d", &proposalID); n == 1 {
+		m.HandleUndo(bot, chatID, proposalID, callback)
 	}
 }
 
-func (m *ModerationHandler) HandleApprove(bot *telego.Bot, chatID int64, messageID int, callback *telego.CallbackQuery) {
-	message, err := m.db.GetMessageByID(messageID)
+func (m *ModerationHandler) HandleApprove(bot *telego.Bot, chatID int64, proposalID uint, callback *telego.CallbackQuery) {
+	tr := m.i18n.For(&callback.From)
+
+	message, err := m.db.GetMessageByID(proposalID)
 	if err != nil {
 		bot.AnswerCallbackQuery(tu.CallbackQuery(
 			callback.ID,
-		).WithText("❌ Ошибка: предложение не найдено"))
+		).WithText(tr.T("proposal_not_found")))
 		return
 	}
 
-	if err := m.media.PublishMedia(bot, m.channelID, message); err != nil {
-		log.Printf("Ошибка отправки в канал: %v", err)
+	// Автор изменил предложение после того, как модератор увидел карточку: показываем новую версию
+	if message.EditedAt != nil && message.EditedAt.Unix() >= callback.Message.Date {
 		bot.AnswerCallbackQuery(tu.CallbackQuery(
 			callback.ID,
-		).WithText("❌ Ошибка при публикации"))
+		).WithText(tr.T("proposal_changed")).WithShowAlert())
+
+		bot.DeleteMessage(tu.Delete(tu.ID(chatID), callback.Message.MessageID))
+		m.SendMessageForModeration(bot, chatID, message, tr)
 		return
 	}
 
-	m.db.UpdateMessageStatus(messageID, "approved")
-	m.db.DeleteMessage(messageID)
+	if delay := m.settings.Int(settings.KeyUndoSeconds); delay > 0 {
+		m.scheduleApprove(bot, chatID, proposalID, callback, tr, time.Duration(delay)*time.Second)
+		return
+	}
+
+	// Статус меняется до публикации, чтобы два модератора не опубликовали предложение дважды
+	if !m.claimPending(bot, callback, tr, proposalID, "approved") {
+		return
+	}
+
+	if err := m.publish(bot, message); err != nil {
+		slog.Error("Ошибка отправки в канал", "proposal", proposalID, "error", err)
+		m.db.ReturnToPending(proposalID, "approved", message.SenderSeal)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("publish_error")))
+		return
+	}
 
 	bot.AnswerCallbackQuery(tu.CallbackQuery(
 		callback.ID,
-	).WithText("✅ Предложение опубликовано!"))
+	).WithText(tr.T("proposal_published")))
+	m.logAction(proposalID, callback.From.ID, "approve", "")
 
 	bot.DeleteMessage(&telego.DeleteMessageParams{
 		ChatID:    tu.ID(chatID),
 		MessageID: callback.Message.MessageID,
 	})
 
-	m.ShowProposals(bot, chatID, callback.From.ID)
+	m.ShowProposals(bot, chatID, &callback.From)
 }
 
-func (m *ModerationHandler) HandleReject(bot *telego.Bot, chatID int64, messageID int, callback *telego.CallbackQuery) {
-	_, err := m.db.GetMessageByID(messageID)
+func (m *ModerationHandler) HandleReject(bot *telego.Bot, chatID int64, proposalID uint, callback *telego.CallbackQuery) {
+	tr := m.i18n.For(&callback.From)
+
+	_, err := m.db.GetMessageByID(proposalID)
 	if err != nil {
 		bot.AnswerCallbackQuery(tu.CallbackQuery(
 			callback.ID,
-		).WithText("❌ Ошибка: предложение не найдено"))
+		).WithText(tr.T("proposal_not_found")))
 		return
 	}
 
-	m.db.UpdateMessageStatus(messageID, "rejected")
-	m.db.DeleteMessage(messageID)
+	if !m.claimPending(bot, callback, tr, proposalID, "rejected") {
+		return
+	}
 
 	bot.AnswerCallbackQuery(tu.CallbackQuery(
 		callback.ID,
-	).WithText("✅ Предложение отклонено!"))
+	).WithText(tr.T("proposal_rejected")))
+	m.logAction(proposalID, callback.From.ID, "reject", "")
 
 	bot.DeleteMessage(&telego.DeleteMessageParams{
 		ChatID:    tu.ID(chatID),
 		MessageID: callback.Message.MessageID,
 	})
 
-	m.ShowProposals(bot, chatID, callback.From.ID)
+	m.ShowProposals(bot, chatID, &callback.From)
+}
+
+// claimPending переводит предложение из очереди в статус status. Если предложение уже
+// обработано (другим модератором или отозвано автором), карточка убирается.
+func (m *ModerationHandler) claimPending(bot *telego.Bot, callback *telego.CallbackQuery, tr i18n.Printer, proposalID uint, status string) bool {
+	ok, err := m.db.UpdateMessageStatus(proposalID, "pending", status)
+	return m.checkClaim(bot, callback, tr, proposalID, ok, err)
+}
+
+func (m *ModerationHandler) checkClaim(bot *telego.Bot, callback *telego.CallbackQuery, tr i18n.Printer, proposalID uint, ok bool, err error) bool {
+	if err != nil {
+		slog.Error("Ошибка обновления статуса предложения", "proposal", proposalID, "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_status_error")))
+		return false
+	}
+	if ok {
+		return true
+	}
+
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("proposal_already_decided")))
+
+	bot.DeleteMessage(&telego.DeleteMessageParams{
+		ChatID:    tu.ID(callback.Message.Chat.ID),
+		MessageID: callback.Message.MessageID,
+	})
+	return false
+}
+
+// publish отправляет предложение в канал и запоминает сообщения поста, чтобы его можно
+// было изменить или удалить. Если пост отправился не целиком, отправленная часть удаляется.
+func (m *ModerationHandler) publish(bot *telego.Bot, message database.Message) error {
+	posts, err := m.renderer.Publish(bot, m.settings.ChannelID(), message)
+	if err != nil {
+		deletePosts(bot, posts)
+		return err
+	}
+
+	if err := m.db.SaveChannelPosts(message.ID, posts); err != nil {
+		slog.Error("Ошибка сохранения сообщений поста", "proposal", message.ID, "error", err)
+	}
+	return nil
+}
+
+// deletePosts удаляет сообщения поста и возвращает те, что удалось удалить, и первую ошибку
+func deletePosts(bot *telego.Bot, posts []database.ChannelPost) (deleted []database.ChannelPost, err error) {
+	for _, post := range posts {
+		if deleteErr := bot.DeleteMessage(tu.Delete(tu.ID(post.ChatID), post.MessageID)); deleteErr != nil {
+			if err == nil {
+				err = deleteErr
+			}
+			continue
+		}
+		deleted = append(deleted, post)
+	}
+	return deleted, err
+}
+
+// logAction записывает действие модератора в журнал
+func (m *ModerationHandler) logAction(proposalID uint, actorID int64, action, details string) {
+	if err := m.db.LogAction(proposalID, actorID, action, details); err != nil {
+		slog.Error("Ошибка записи в журнал действий", "proposal", proposalID, "error", err)
+	}
+	slog.Info("📝 Действие модератора", logger.User(actorID), "action", action, "proposal", proposalID)
 }
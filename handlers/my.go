@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"telegram-bot/anon"
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const myProposalsLimit = 20
+
+// HandleMyCommand показывает пользователю его предложения. Поиск идёт только по анонимной
+// ссылке на отправителя, поэтому Telegram ID нигде не сохраняется.
+func (p *ProposalsHandler) HandleMyCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := p.i18n.For(msg.From)
+
+	text, keyboard := p.renderMyProposals(bot, msg.From.ID, tr)
+	params := tu.Message(tu.ID(msg.Chat.ID), text)
+	if keyboard != nil {
+		params = params.WithReplyMarkup(keyboard)
+	}
+	bot.SendMessage(params)
+}
+
+func (p *ProposalsHandler) renderMyProposals(bot *telego.Bot, userID int64, tr i18n.Printer) (string, *telego.InlineKeyboardMarkup) {
+	messages, err := p.db.GetMessagesBySender(p.anon.Ref(userID), myProposalsLimit)
+	if err != nil {
+		slog.Error("Ошибка получения предложений пользователя", "error", err)
+		return tr.T("my_error"), nil
+	}
+
+	if len(messages) == 0 {
+		return tr.T("my_empty"), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("my_header"))
+
+	var rows [][]telego.InlineKeyboardButton
+	for _, message := range messages {
+		text.WriteString(fmt.Sprintf("#%d · %s · %s\n%s\n",
+			message.ID,
+			message.CreatedAt.Format("02.01.2006 15:04"),
+			tr.T("status_"+message.Status),
+			snippet(message.MessageText, 60),
+		))
+		if message.Status == "approved" && len(message.Posts) > 0 {
+			if link := p.renderer.PostLink(bot, message.Posts[0]); link != "" {
+				text.WriteString(tr.T("my_post_link", link))
+			}
+		}
+		text.WriteString("\n")
+
+		if message.Status == "pending" {
+			rows = append(rows, tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(tr.T("btn_withdraw", message.ID)).WithCallbackData(fmt.Sprintf("withdraw_%d", message.ID)),
+			))
+		}
+	}
+
+	if len(rows) == 0 {
+		return text.String(), nil
+	}
+	return text.String(), tu.InlineKeyboard(rows...)
+}
+
+func (p *ProposalsHandler) HandleWithdrawCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := p.i18n.For(&callback.From)
+
+	var proposalID uint
+	if n, _ := fmt.Sscanf(callback.Data, "withdraw_%d", &proposalID); n != 1 {
+		return
+	}
+
+	message, err := p.db.GetMessageByID(proposalID)
+	if err != nil || message.SenderRef != p.anon.Ref(callback.From.ID) {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_not_found")))
+		return
+	}
+
+	ok, err := p.db.UpdateMessageStatus(proposalID, "pending", "withdrawn")
+	if err != nil {
+		slog.Error("Ошибка отзыва предложения", "proposal", proposalID, "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_status_error")))
+		return
+	}
+	if !ok {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_already_decided")))
+	} else {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_withdrawn", proposalID)))
+		p.unstage(bot, message)
+	}
+
+	text, keyboard := p.renderMyProposals(bot, callback.From.ID, tr)
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:      tu.ID(callback.Message.Chat.ID),
+		MessageID:   callback.Message.MessageID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	})
+}
+
+// snippet обрезает текст до limit символов для списков
+func snippet(text string, limit int) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	if runes := []rune(text); len(runes) > limit {
+		return "«" + string(runes[:limit]) + "…»"
+	}
+	return "«" + text + "»"
+}
+
+// HandleStatusCommand показывает статус предложения по коду отслеживания: /status <код>
+func (p *ProposalsHandler) HandleStatusCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := p.i18n.For(msg.From)
+
+	_, args := tu.ParseCommand(msg.Text)
+	if len(args) == 0 {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("ticket_usage"),
+		))
+		return
+	}
+
+	ticket := anon.NormalizeTicket(strings.Join(args, ""))
+	message, err := p.db.GetMessageByTicket(ticket)
+	if err != nil {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("ticket_not_found"),
+		))
+		return
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("ticket_status",
+			message.Ticket,
+			tr.T("status_"+message.Status),
+			message.CreatedAt.Format("02.01.2006 15:04"),
+		),
+	))
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	notifyImmediate = "immediate"
+	notifyDigest    = "digest"
+	notifyMuted     = "muted"
+
+	defaultDigestMinutes = 60
+)
+
+// Notifier рассылает модераторам уведомления о новых предложениях с учётом их настроек:
+// сразу, сводкой раз в N минут или никогда, с паузой на часы тишины
+type Notifier struct {
+	db       database.Storage
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	ownerID  int64
+
+	// remindedAt и escalatedAt - время последних напоминаний о застоявшейся очереди,
+	// их меняет только фоновая проверка
+	remindedAt  time.Time
+	escalatedAt time.Time
+}
+
+func NewNotifier(db database.Storage, store *settings.Store, localizer *i18n.Localizer, ownerID int64) *Notifier {
+	return &Notifier{
+		db:       db,
+		settings: store,
+		i18n:     localizer,
+		ownerID:  ownerID,
+	}
+}
+
+// NotifyNewProposal сразу уведомляет модераторов, выбравших режим "сразу"
+func (n *Notifier) NotifyNewProposal(bot *telego.Bot, message *database.Message) {
+	if !n.settings.Bool(settings.KeyNotifyAdmins) {
+		return
+	}
+
+	admins, err := n.db.GetAdmins()
+	if err != nil {
+		slog.Error("Ошибка получения списка администраторов", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, admin := range admins {
+		preference, _, err := n.db.GetNotifyPreference(admin.UserID)
+		if err != nil {
+			slog.Error("Ошибка получения настроек уведомлений", logger.User(admin.UserID), "error", err)
+			continue
+		}
+		if notifyMode(preference) != notifyImmediate || isQuiet(preference, now) {
+			continue
+		}
+
+		notification := n.i18n.ForUserID(admin.UserID).T(
+			"admin_new_proposal",
+			message.MessageText,
+			message.MediaType,
+		)
+
+		_, err = bot.SendMessage(tu.Message(
+			tu.ID(admin.UserID),
+			notification,
+		))
+		if err != nil {
+			slog.Warn("Ошибка отправки уведомления администратору", logger.User(admin.UserID), "error", err)
+		}
+		n.markNotified(admin.UserID, now)
+	}
+}
+
+// SendDigests отправляет сводки модераторам в режиме "сводка", а также тем, кто в режиме
+// "сразу" пропустил предложения во время часов тишины
+func (n *Notifier) SendDigests(bot *telego.Bot) {
+	if !n.settings.Bool(settings.KeyNotifyAdmins) {
+		return
+	}
+
+	admins, err := n.db.GetAdmins()
+	if err != nil {
+		slog.Error("Ошибка получения списка администраторов", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, admin := range admins {
+		preference, _, err := n.db.GetNotifyPreference(admin.UserID)
+		if err != nil {
+			slog.Error("Ошибка получения настроек уведомлений", logger.User(admin.UserID), "error", err)
+			continue
+		}
+
+		mode := notifyMode(preference)
+		if mode == notifyMuted || isQuiet(preference, now) {
+			continue
+		}
+		if preference.NotifiedAt == nil {
+			// Отсчёт начинается с первого запуска, старая очередь сводкой не присылается
+			n.markNotified(admin.UserID, now)
+			continue
+		}
+		if mode == notifyDigest && now.Sub(*preference.NotifiedAt) < digestInterval(preference) {
+			continue
+		}
+
+		count, err := n.db.CountPendingSince(*preference.NotifiedAt)
+		if err != nil {
+			slog.Error("Ошибка подсчёта новых предложений", "error", err)
+			return
+		}
+		if count > 0 {
+			n.sendDigest(bot, admin.UserID, count, now)
+		}
+		n.markNotified(admin.UserID, now)
+	}
+}
+
+func (n *Notifier) sendDigest(bot *telego.Bot, userID int64, count int64, now time.Time) {
+	tr := n.i18n.ForUserID(userID)
+
+	age := ""
+	if oldest, ok, err := n.db.GetOldestPending(); err == nil && ok {
+		age = formatAge(tr, now.Sub(oldest.CreatedAt))
+	}
+
+	_, err := bot.SendMessage(tu.Message(
+		tu.ID(userID),
+		tr.T("notify_digest_message", count, age),
+	))
+	if err != nil {
+		slog.Warn("Ошибка отправки сводки администратору", logger.User(userID), "error", err)
+	}
+}
+
+// CheckStaleQueue напоминает модераторам, если самое старое предложение ждёт дольше
+// stale_minutes, и сообщает владельцу, если дольше escalate_minutes. Напоминания
+// повторяются не чаще, чем раз в соответствующий интервал.
+func (n *Notifier) CheckStaleQueue(bot *telego.Bot) {
+	oldest, ok, err := n.db.GetOldestPending()
+	if err != nil {
+		slog.Error("Ошибка проверки очереди предложений", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	age := now.Sub(oldest.CreatedAt)
+
+	if stale := time.Duration(n.settings.Int(settings.KeyStaleMinutes)) * time.Minute; stale > 0 && age >= stale && now.Sub(n.remindedAt) >= stale {
+		n.remindedAt = now
+		n.remindModerators(bot, age, now)
+	}
+
+	if escalate := time.Duration(n.settings.Int(settings.KeyEscalateMinutes)) * time.Minute; escalate > 0 && age >= escalate && now.Sub(n.escalatedAt) >= escalate {
+		n.escalatedAt = now
+		tr := n.i18n.ForUserID(n.ownerID)
+		_, err := bot.SendMessage(tu.Message(
+			tu.ID(n.ownerID),
+			tr.T("queue_escalation", oldest.ID, formatAge(tr, age)),
+		))
+		if err != nil {
+			slog.Warn("Ошибка отправки эскалации владельцу", "error", err)
+		}
+	}
+}
+
+func (n *Notifier) remindModerators(bot *telego.Bot, age time.Duration, now time.Time) {
+	admins, err := n.db.GetAdmins()
+	if err != nil {
+		slog.Error("Ошибка получения списка администраторов", "error", err)
+		return
+	}
+
+	for _, admin := range admins {
+		preference, _, err := n.db.GetNotifyPreference(admin.UserID)
+		if err != nil || notifyMode(preference) == notifyMuted || isQuiet(preference, now) {
+			continue
+		}
+
+		tr := n.i18n.ForUserID(admin.UserID)
+		_, err = bot.SendMessage(tu.Message(
+			tu.ID(admin.UserID),
+			tr.T("queue_reminder", formatAge(tr, age)),
+		))
+		if err != nil {
+			slog.Warn("Ошибка отправки напоминания администратору", logger.User(admin.UserID), "error", err)
+		}
+	}
+}
+
+func (n *Notifier) markNotified(userID int64, now time.Time) {
+	if err := n.db.SetNotifiedAt(userID, now); err != nil {
+		slog.Error("Ошибка сохранения времени уведомления", logger.User(userID), "error", err)
+	}
+}
+
+// HandleNotifyCommand показывает и меняет настройки уведомлений:
+// /notify, /notify now, /notify digest <минуты>, /notify mute, /notify quiet <с>-<до>|off
+func (n *Notifier) HandleNotifyCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := n.i18n.For(msg.From)
+
+	if !n.db.IsAdmin(msg.From.ID) && msg.From.ID != n.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	preference, _, err := n.db.GetNotifyPreference(msg.From.ID)
+	if err != nil {
+		slog.Error("Ошибка получения настроек уведомлений", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("notify_error"),
+		))
+		return
+	}
+
+	_, args := tu.ParseCommand(msg.Text)
+	if len(args) > 0 {
+		if !applyNotifyArgs(&preference, args) {
+			bot.SendMessage(tu.Message(
+				tu.ID(msg.Chat.ID),
+				tr.T("notify_usage"),
+			))
+			return
+		}
+		if err := n.db.SaveNotifyPreference(preference); err != nil {
+			slog.Error("Ошибка сохранения настроек уведомлений", "error", err)
+			bot.SendMessage(tu.Message(
+				tu.ID(msg.Chat.ID),
+				tr.T("notify_error"),
+			))
+			return
+		}
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		n.describe(preference, tr),
+	).WithReplyMarkup(notifyKeyboard(tr)))
+}
+
+// HandleNotifyCallback переключает режим уведомлений кнопками под /notify
+func (n *Notifier) HandleNotifyCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := n.i18n.For(&callback.From)
+
+	if !n.db.IsAdmin(callback.From.ID) && callback.From.ID != n.ownerID {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("callback_no_access")))
+		return
+	}
+
+	mode := strings.TrimPrefix(callback.Data, "notify_")
+	switch mode {
+	case notifyImmediate, notifyDigest, notifyMuted:
+	default:
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("notify_unknown_mode")))
+		return
+	}
+
+	preference, _, err := n.db.GetNotifyPreference(callback.From.ID)
+	if err == nil {
+		preference.Mode = mode
+		if preference.Mode == notifyDigest && preference.DigestMinutes <= 0 {
+			preference.DigestMinutes = defaultDigestMinutes
+		}
+		err = n.db.SaveNotifyPreference(preference)
+	}
+	if err != nil {
+		slog.Error("Ошибка сохранения настроек уведомлений", "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("notify_error")))
+		return
+	}
+
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("notify_saved")))
+
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:      tu.ID(callback.Message.Chat.ID),
+		MessageID:   callback.Message.MessageID,
+		Text:        n.describe(preference, tr),
+		ReplyMarkup: notifyKeyboard(tr),
+	})
+}
+
+func (n *Notifier) describe(preference database.NotifyPreference, tr i18n.Printer) string {
+	var mode string
+	if notifyMode(preference) == notifyDigest {
+		mode = tr.T("notify_mode_digest_every", int(digestInterval(preference).Minutes()))
+	} else {
+		mode = tr.T("notify_mode_" + notifyMode(preference))
+	}
+
+	quiet := tr.T("notify_quiet_off")
+	if preference.QuietFrom != preference.QuietTo {
+		quiet = fmt.Sprintf("%02d:00-%02d:00", preference.QuietFrom, preference.QuietTo)
+	}
+
+	text := tr.T("notify_info", mode, quiet)
+	if !n.settings.Bool(settings.KeyNotifyAdmins) {
+		text += "\n\n" + tr.T("notify_globally_off")
+	}
+	return text
+}
+
+func notifyKeyboard(tr i18n.Printer) *telego.InlineKeyboardMarkup {
+	return tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(tr.T("btn_notify_immediate")).WithCallbackData("notify_"+notifyImmediate),
+			tu.InlineKeyboardButton(tr.T("btn_notify_digest")).WithCallbackData("notify_"+notifyDigest),
+			tu.InlineKeyboardButton(tr.T("btn_notify_muted")).WithCallbackData("notify_"+notifyMuted),
+		),
+	)
+}
+
+func applyNotifyArgs(preference *database.NotifyPreference, args []string) bool {
+	switch {
+	case args[0] == "now" && len(args) == 1:
+		preference.Mode = notifyImmediate
+	case args[0] == "mute" && len(args) == 1:
+		preference.Mode = notifyMuted
+	case args[0] == "digest" && len(args) <= 2:
+		minutes := defaultDigestMinutes
+		if len(args) == 2 {
+			value, err := strconv.Atoi(args[1])
+			if err != nil || value < 1 || value > 24*60 {
+				return false
+			}
+			minutes = value
+		}
+		preference.Mode = notifyDigest
+		preference.DigestMinutes = minutes
+	case args[0] == "quiet" && len(args) == 2:
+		if args[1] == "off" {
+			preference.QuietFrom, preference.QuietTo = 0, 0
+			return true
+		}
+		var from, to int
+		if n, _ := fmt.Sscanf(args[1], "%d-%d", &from, &to); n != 2 || from < 0 || from > 23 || to < 0 || to > 23 {
+			return false
+		}
+		preference.QuietFrom, preference.QuietTo = from, to
+	default:
+		return false
+	}
+	return true
+}
+
+func notifyMode(preference database.NotifyPreference) string {
+	switch preference.Mode {
+	case notifyDigest, notifyMuted:
+		return preference.Mode
+	default:
+		return notifyImmediate
+	}
+}
+
+func digestInterval(preference database.NotifyPreference) time.Duration {
+	if preference.DigestMinutes <= 0 {
+		return defaultDigestMinutes * time.Minute
+	}
+	return time.Duration(preference.DigestMinutes) * time.Minute
+}
+
+// isQuiet проверяет, попадает ли now в часы тишины модератора. Интервал может
+// переходить через полночь, например 23-8.
+func isQuiet(preference database.NotifyPreference, now time.Time) bool {
+	from, to, hour := preference.QuietFrom, preference.QuietTo, now.Hour()
+	if from == to {
+		return false
+	}
+	if from < to {
+		return hour >= from && hour < to
+	}
+	return hour >= from || hour < to
+}
+
+// formatAge выводит возраст предложения в минутах, часах или днях
+func formatAge(tr i18n.Printer, age time.Duration) string {
+	minutes := int(age.Minutes())
+	switch {
+	case minutes < 60:
+		return tr.T("age_minutes", minutes)
+	case minutes < 24*60:
+		return tr.T("age_hours", minutes/60, minutes%60)
+	default:
+		return tr.T("age_days", minutes/(24*60), minutes%(24*60)/60)
+	}
+}
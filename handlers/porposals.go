@@ -1,47 +1,42 @@
 package handlers
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"telegram-bot/anon"
 	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/logger"
+	"telegram-bot/settings"
 
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
-const welcomeText = `🤖 Добро пожаловать в анонимную предложку!
-
-Просто отправьте сюда ваше предложение, идею или сообщение, и оно будет анонимно рассмотрено модераторами.
-
-Ваша личность будет скрыта - модераторы увидят только содержание вашего сообщения.
-
-❓ Что можно отправлять:
-• Текстовые предложения
-• Фотографии
-• Документы
-• Видео
-• Кружочки (видеосообщения)
-• Аудио и голосовые сообщения
-• Стикеры
-• Идеи и пожелания
-
-Ваше предложение будет рассмотрено в ближайшее время!`
-
 type ProposalsHandler struct {
-	db        *database.Database
-	media     *MediaHandler
-	channelID int64
-	ownerID   int64
+	db       database.Storage
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	media    *MediaHandler
+	renderer *Renderer
+	notifier *Notifier
+	anon     *anon.Keeper
+	drafts   *DraftStore
+	ownerID  int64
 }
 
-func NewProposalsHandler(db *database.Database, media *MediaHandler, channelID, ownerID int64) *ProposalsHandler {
+func NewProposalsHandler(db database.Storage, store *settings.Store, localizer *i18n.Localizer, media *MediaHandler, renderer *Renderer, notifier *Notifier, keeper *anon.Keeper, ownerID int64) *ProposalsHandler {
 	return &ProposalsHandler{
-		db:        db,
-		media:     media,
-		channelID: channelID,
-		ownerID:   ownerID,
+		db:       db,
+		settings: store,
+		i18n:     localizer,
+		media:    media,
+		renderer: renderer,
+		notifier: notifier,
+		anon:     keeper,
+		drafts:   NewDraftStore(),
+		ownerID:  ownerID,
 	}
 }
 
@@ -72,11 +67,29 @@ func (p *ProposalsHandler) HandleUserProposal(bot *telego.Bot, update telego.Upd
 		return
 	}
 
-	log.Printf("📨 Новое предложение от пользователя %d", userID)
+	slog.Info("📨 Новое предложение", logger.User(userID))
+	tr := p.i18n.For(msg.From)
+
+	forwardSource := p.media.ForwardSource(msg)
+	if forwardSource != "" && p.settings.Get(settings.KeyForwardPolicy) == settings.ForwardReject {
+		bot.SendMessage(tu.Message(
+			tu.ID(chatID),
+			tr.T("forward_rejected"),
+		))
+		return
+	}
 
 	mediaType, mediaFileID := p.media.GetMediaInfo(msg)
 	messageText := p.media.ExtractMessageText(msg)
 
+	if maxLength := p.settings.Int(settings.KeyMaxTextLength); maxLength > 0 && int64(len([]rune(messageText))) > maxLength {
+		bot.SendMessage(tu.Message(
+			tu.ID(chatID),
+			tr.T("proposal_too_long", maxLength),
+		))
+		return
+	}
+
 	message := &database.Message{
 		MessageID:   msg.MessageID,
 		MessageText: messageText,
@@ -84,52 +97,72 @@ func (p *ProposalsHandler) HandleUserProposal(bot *telego.Bot, update telego.Upd
 		MediaFileID: mediaFileID,
 		CreatedAt:   time.Now(),
 		Status:      "pending",
-		ChannelID:   p.channelID,
+		ChannelID:   p.settings.ChannelID(),
+		SenderRef:   p.anon.Ref(userID),
+		// Источник пересылки виден только модераторам, если политика не требует указывать его в посте
+		ForwardSource: forwardSource,
 	}
 
-	if err := p.db.SaveMessage(message); err != nil {
-		log.Printf("Ошибка сохранения предложения: %v", err)
+	switch p.settings.Get(settings.KeySubmissionMode) {
+	case settings.SubmissionConfirm:
+		p.saveDraft(bot, msg, tr, message)
+	case settings.SubmissionDraft:
+		p.collectDraft(bot, msg, tr, message)
+	default:
+		p.submitProposal(bot, chatID, tr, message)
+	}
+}
+
+// submitProposal сохраняет предложение в очередь модерации и уведомляет модераторов
+func (p *ProposalsHandler) submitProposal(bot *telego.Bot, chatID int64, tr i18n.Printer, message *database.Message) {
+	// Подтверждённый черновик уже скопирован в служебный чат для предпросмотра
+	if message.StagingMessageID == 0 {
+		p.renderer.Stage(bot, chatID, message)
+	}
+
+	if p.settings.Bool(settings.KeyExpireNotify) && p.settings.Int(settings.KeyPendingTTLDays) > 0 {
+		seal, err := p.anon.Seal(chatID)
+		if err != nil {
+			slog.Error("Ошибка шифрования ID автора", "error", err)
+		}
+		message.SenderSeal = seal
+	}
+
+	ticket, err := anon.NewTicket()
+	if err == nil {
+		message.Ticket = ticket
+		err = p.db.SaveMessage(message)
+	}
+	if err != nil {
+		slog.Error("Ошибка сохранения предложения", "error", err)
+		// На копию не ссылается ни одна запись, очистка по срокам её не найдёт
+		p.renderer.Unstage(bot, *message)
 		bot.SendMessage(tu.Message(
 			tu.ID(chatID),
-			"❌ Произошла ошибка при отправке предложения. Попробуйте позже.",
+			tr.T("proposal_save_error"),
 		))
 		return
 	}
 
 	bot.SendMessage(tu.Message(
 		tu.ID(chatID),
-		"✅ Ваше предложение принято! Оно будет рассмотрено модераторами анонимно.",
+		tr.T("proposal_accepted", message.Ticket, message.Ticket),
 	))
 
-	log.Printf("✅ Предложение сохранено: %s (тип: %s)", messageText, mediaType)
+	slog.Info("✅ Предложение сохранено", "proposal", message.ID, "type", message.MediaType, logger.Content(message.MessageText))
 
-	p.notifyAdminsAboutNewProposal(bot, message)
+	p.notifier.NotifyNewProposal(bot, message)
 }
 
-func (p *ProposalsHandler) notifyAdminsAboutNewProposal(bot *telego.Bot, message *database.Message) {
-	admins, err := p.db.GetAdmins()
-	if err != nil {
-		log.Printf("Ошибка получения списка администраторов: %v", err)
+// unstage удаляет копию предложения, которое уже не будет опубликовано, из служебного чата
+func (p *ProposalsHandler) unstage(bot *telego.Bot, message database.Message) {
+	if message.StagingMessageID == 0 {
 		return
 	}
 
-	notification := fmt.Sprintf(
-		"📨 Поступило новое анонимное предложение!\n\n"+
-			"💬 Текст: %s\n"+
-			"📁 Тип: %s\n\n"+
-			"Используйте /proposals для просмотра всех предложений.",
-		message.MessageText,
-		message.MediaType,
-	)
-
-	for _, admin := range admins {
-		_, err := bot.SendMessage(tu.Message(
-			tu.ID(admin.UserID),
-			notification,
-		))
-		if err != nil {
-			log.Printf("Ошибка отправки уведомления администратору %d: %v", admin.UserID, err)
-		}
+	p.renderer.Unstage(bot, message)
+	if err := p.db.ClearStagedCopy(message.ID); err != nil {
+		slog.Error("Ошибка удаления ссылки на копию предложения", "proposal", message.ID, "error", err)
 	}
 }
 
@@ -142,23 +175,18 @@ func (p *ProposalsHandler) HandleStartCommand(bot *telego.Bot, update telego.Upd
 	userID := msg.From.ID
 	chatID := msg.Chat.ID
 
-	log.Printf("Обработка /start от пользователя %d", userID)
+	slog.Debug("Обработка /start", logger.User(userID))
+	tr := p.i18n.For(msg.From)
 
 	if p.db.IsAdmin(userID) || userID == p.ownerID {
 
 		var messageText string
 
 		if userID == p.ownerID {
-			messageText = "👑 Панель владельца\n\nЭто бот для анонимных предложений. Пользователи присылают предложения в ЛС, а вы их модерируете.\n\n" +
-				"Доступные команды:\n" +
-				"/addadmin <ID> - добавить администратора\n" +
-				"/admins - список администраторов\n" +
-				"/proposals - просмотр предложений"
+			messageText = tr.T("owner_panel")
 
 		} else {
-			messageText = "🛠️ Панель модератора\n\nЭто бот для анонимных предложений. Пользователи присылают предложения в ЛС, а вы их модерируете.\n\n" +
-				"Доступные команды:\n" +
-				"/proposals - просмотр предложений"
+			messageText = tr.T("moderator_panel")
 		}
 
 		bot.SendMessage(tu.Message(
@@ -169,7 +197,7 @@ func (p *ProposalsHandler) HandleStartCommand(bot *telego.Bot, update telego.Upd
 
 		bot.SendMessage(tu.Message(
 			tu.ID(chatID),
-			welcomeText,
+			tr.T("welcome"),
 		))
 	}
 }
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	postEditInputPrefix = "post_edit:"
+	postHistoryLimit    = 10
+)
+
+// HandlePostCommand показывает опубликованный пост с кнопками правки и удаления: /post <номер>
+func (m *ModerationHandler) HandlePostCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := m.i18n.For(msg.From)
+
+	if !m.db.IsAdmin(msg.From.ID) && msg.From.ID != m.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	_, args := tu.ParseCommand(msg.Text)
+	if len(args) != 1 {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("post_usage"),
+		))
+		return
+	}
+
+	proposalID, err := strconv.ParseUint(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("post_usage"),
+		))
+		return
+	}
+
+	m.showPost(bot, msg.Chat.ID, uint(proposalID), tr)
+}
+
+func (m *ModerationHandler) showPost(bot *telego.Bot, chatID int64, proposalID uint, tr i18n.Printer) {
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil {
+		bot.SendMessage(tu.Message(
+			tu.ID(chatID),
+			tr.T("proposal_not_found"),
+		))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("post_info", message.ID, tr.T("status_"+message.Status), len(message.Posts), snippet(message.MessageText, 200)))
+
+	actions, err := m.db.GetActions(message.ID, postHistoryLimit)
+	if err != nil {
+		slog.Error("Ошибка получения журнала действий", "proposal", message.ID, "error", err)
+	}
+	if len(actions) > 0 {
+		text.WriteString("\n\n" + tr.T("post_history"))
+		for _, action := range actions {
+			text.WriteString(fmt.Sprintf("\n• %s · %s · ID %d",
+				action.CreatedAt.Format("02.01.2006 15:04"),
+				tr.T("action_"+action.Action),
+				action.ActorID,
+			))
+		}
+	}
+
+	params := tu.Message(tu.ID(chatID), text.String())
+	if message.Status == "approved" && len(message.Posts) > 0 {
+		params = params.WithReplyMarkup(tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(tr.T("btn_post_edit")).WithCallbackData(fmt.Sprintf("post_edit_%d", message.ID)),
+				tu.InlineKeyboardButton(tr.T("btn_post_delete")).WithCallbackData(fmt.Sprintf("post_delete_%d", message.ID)),
+			),
+		))
+	}
+	bot.SendMessage(params)
+}
+
+// HandlePostCallback обрабатывает кнопки управления опубликованным постом
+func (m *ModerationHandler) HandlePostCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := m.i18n.For(&callback.From)
+
+	if !m.db.IsAdmin(callback.From.ID) && callback.From.ID != m.ownerID {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("callback_no_access")))
+		return
+	}
+
+	chatID := callback.Message.Chat.ID
+	var proposalID uint
+
+	switch {
+	case scanProposal(callback.Data, "post_edit_%d", &proposalID):
+		m.inputs.Wait(callback.From.ID, fmt.Sprintf("%s%d", postEditInputPrefix, proposalID))
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		bot.SendMessage(tu.Message(
+			tu.ID(chatID),
+			tr.T("post_edit_prompt", proposalID),
+		))
+
+	case scanProposal(callback.Data, "post_delete_confirm_%d", &proposalID):
+		m.deletePost(bot, callback, proposalID, tr)
+
+	case scanProposal(callback.Data, "post_delete_%d", &proposalID):
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		bot.EditMessageReplyMarkup(&telego.EditMessageReplyMarkupParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: callback.Message.MessageID,
+			ReplyMarkup: tu.InlineKeyboard(
+				tu.InlineKeyboardRow(
+					tu.InlineKeyboardButton(tr.T("btn_post_delete_confirm")).WithCallbackData(fmt.Sprintf("post_delete_confirm_%d", proposalID)),
+				),
+			),
+		})
+	}
+}
+
+func (m *ModerationHandler) deletePost(bot *telego.Bot, callback *telego.CallbackQuery, proposalID uint, tr i18n.Printer) {
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_not_found")))
+		return
+	}
+
+	ok, err := m.db.UpdateMessageStatus(proposalID, "approved", "deleted")
+	if err != nil {
+		slog.Error("Ошибка обновления статуса предложения", "proposal", proposalID, "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_status_error")))
+		return
+	}
+	if !ok {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_already_decided")))
+		return
+	}
+
+	if deleted, err := deletePosts(bot, message.Posts); err != nil {
+		// Telegram не даёт удалять сообщения старше 48 часов - сообщаем модератору.
+		// Пост остаётся опубликованным из тех сообщений, которые удалить не удалось.
+		slog.Warn("Ошибка удаления поста из канала", "proposal", proposalID, "deleted", len(deleted), "error", err)
+		ids := make([]uint, 0, len(deleted))
+		for _, post := range deleted {
+			ids = append(ids, post.ID)
+		}
+		if err := m.db.DeleteChannelPosts(ids); err != nil {
+			slog.Error("Ошибка удаления сообщений поста", "proposal", proposalID, "error", err)
+		}
+		m.db.UpdateMessageStatus(proposalID, "deleted", "approved")
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("post_delete_error")).WithShowAlert())
+		return
+	}
+
+	m.logAction(proposalID, callback.From.ID, "delete", "")
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("post_deleted")))
+
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    tu.ID(callback.Message.Chat.ID),
+		MessageID: callback.Message.MessageID,
+		Text:      tr.T("post_deleted"),
+	})
+}
+
+// HandlePostEditInput применяет новый текст к опубликованному посту
+func (m *ModerationHandler) HandlePostEditInput(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	action, ok := m.inputs.Take(msg.From.ID)
+	if !ok || (!m.db.IsAdmin(msg.From.ID) && msg.From.ID != m.ownerID) {
+		return
+	}
+	tr := m.i18n.For(msg.From)
+
+	var proposalID uint
+	if n, _ := fmt.Sscanf(action, postEditInputPrefix+"%d", &proposalID); n != 1 {
+		return
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		m.inputs.Wait(msg.From.ID, action)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("post_edit_empty"),
+		))
+		return
+	}
+
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil || message.Status != "approved" {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("post_not_published"),
+		))
+		return
+	}
+
+	previous := message.MessageText
+	message.MessageText = text
+	if err := m.renderer.EditText(bot, message); err != nil {
+		slog.Warn("Ошибка изменения поста", "proposal", proposalID, "error", err)
+		key := "post_edit_error"
+		switch {
+		case errors.Is(err, errNoPostText):
+			key = "post_edit_no_text"
+		case errors.Is(err, errPostTooLong):
+			key = "post_edit_too_long"
+		}
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T(key),
+		))
+		return
+	}
+
+	if err := m.db.UpdateMessageText(proposalID, text); err != nil {
+		slog.Error("Ошибка сохранения текста поста", "proposal", proposalID, "error", err)
+	}
+	m.logAction(proposalID, msg.From.ID, "edit", snippet(previous, 200))
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("post_edited", proposalID),
+	))
+}
+
+func scanProposal(data, format string, id *uint) bool {
+	n, _ := fmt.Sscanf(data, format, id)
+	return n == 1
+}
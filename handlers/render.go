@@ -0,0 +1,702 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	maxTextLength    = 4096
+	maxCaptionLength = 1024
+	maxAlbumSize     = 10
+)
+
+// Подстановки, доступные в шаблонах шапки, подвала и подписи поста
+var templatePlaceholders = []string{"{number}", "{date}", "{channel_link}", "{suggest_link}"}
+
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// Renderer собирает пост из предложения. Один и тот же пост отправляется в канал,
+// автору для предпросмотра и модераторам, поэтому все видят одинаковый результат.
+type Renderer struct {
+	settings *settings.Store
+	i18n     *i18n.Localizer
+
+	// Ссылки для шаблонов запрашиваются у Telegram один раз
+	mu          sync.Mutex
+	links       map[int64]string
+	botUsername string
+}
+
+func NewRenderer(store *settings.Store, localizer *i18n.Localizer) *Renderer {
+	return &Renderer{settings: store, i18n: localizer, links: make(map[int64]string)}
+}
+
+// sendOptions - параметры, с которыми отправляется каждое сообщение поста
+type sendOptions struct {
+	silent  bool
+	protect bool
+	markup  *telego.InlineKeyboardMarkup
+}
+
+// replyMarkup возвращает клавиатуру поста. Пустая клавиатура должна быть nil-интерфейсом,
+// иначе в запрос попадёт "reply_markup": null.
+func (o sendOptions) replyMarkup() telego.ReplyMarkup {
+	if o.markup == nil {
+		return nil
+	}
+	return o.markup
+}
+
+// Типы текста в сообщениях поста, см. database.ChannelPost
+const (
+	postText    = "text"
+	postCaption = "caption"
+)
+
+// Publish отправляет пост в чат chatID и возвращает отправленные сообщения
+func (r *Renderer) Publish(bot *telego.Bot, chatID int64, message database.Message) ([]database.ChannelPost, error) {
+	return r.render(bot, chatID, message, sendOptions{markup: r.keyboard(bot, message)})
+}
+
+// Preview показывает модератору пост в том виде, в котором он будет опубликован.
+// Поверх поста добавляется оформление предпросмотра: сообщения приходят без звука,
+// их нельзя переслать, а если пост отправить не удалось, модератор получает описание.
+// Возвращает ID первого сообщения предпросмотра.
+func (r *Renderer) Preview(bot *telego.Bot, chatID int64, message database.Message, tr i18n.Printer) (int, error) {
+	posts, err := r.render(bot, chatID, message, sendOptions{silent: true, protect: true, markup: r.keyboard(bot, message)})
+	if err == nil && len(posts) > 0 {
+		return posts[0].MessageID, nil
+	}
+	slog.Warn("Ошибка отправки предпросмотра предложения", "proposal", message.ID, "error", err)
+
+	sent, err := bot.SendMessage(tu.Message(
+		tu.ID(chatID),
+		tr.T("media_preview_failed", message.MediaType, message.MessageText),
+	).WithDisableNotification())
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+func (r *Renderer) render(bot *telego.Bot, chatID int64, message database.Message, opts sendOptions) ([]database.ChannelPost, error) {
+	if r.copyable(message) {
+		if id, ok := r.copyStaged(bot, chatID, message, opts); ok {
+			kind := ""
+			switch {
+			case isTextMessage(message):
+				kind = postText
+			case supportsCaption(message.MediaType):
+				kind = postCaption
+			}
+			return []database.ChannelPost{r.sent(chatID, id, kind, opts, true)}, nil
+		}
+	}
+	message = r.withAttribution(message)
+
+	switch {
+	case message.MediaType == "composite":
+		return r.sendComposite(bot, chatID, message, opts)
+	case isTextMessage(message):
+		id, err := r.sendText(bot, chatID, r.messageText(bot, message), opts)
+		return []database.ChannelPost{r.sent(chatID, id, postText, opts, false)}, err
+	case !supportsCaption(message.MediaType):
+		id, err := r.sendMedia(bot, chatID, message.MediaType, message.MediaFileID, "", opts)
+		return []database.ChannelPost{r.sent(chatID, id, "", opts, false)}, err
+	}
+
+	caption := r.postText(bot, message, maxCaptionLength)
+	if tu.UTF16TextLen(caption) <= maxCaptionLength {
+		id, err := r.sendMedia(bot, chatID, message.MediaType, message.MediaFileID, caption, opts)
+		return []database.ChannelPost{r.sent(chatID, id, postCaption, opts, false)}, err
+	}
+
+	// Текст не помещается в подпись: медиа отправляется без подписи, текст с кнопками - следом
+	plain := opts
+	plain.markup = nil
+	mediaID, err := r.sendMedia(bot, chatID, message.MediaType, message.MediaFileID, "", plain)
+	if err != nil {
+		return nil, err
+	}
+	posts := []database.ChannelPost{r.sent(chatID, mediaID, "", plain, false)}
+
+	textID, err := r.sendText(bot, chatID, r.messageText(bot, message), opts)
+	if err != nil {
+		return posts, err
+	}
+	return append(posts, r.sent(chatID, textID, postText, opts, false)), nil
+}
+
+// copyable сообщает, что пост совпадает с исходным сообщением и его можно опубликовать копией.
+// В копию нельзя дописать источник, префикс текстового поста и шаблоны, поэтому оформленный
+// пост собирается заново.
+func (r *Renderer) copyable(message database.Message) bool {
+	if r.hasTemplates() || r.withAttribution(message).MessageText != message.MessageText {
+		return false
+	}
+	return !isTextMessage(message) || r.i18n.Channel().T("publish_text_prefix", message.MessageText) == message.MessageText
+}
+
+func isTextMessage(message database.Message) bool {
+	return message.MediaType == "text" || message.MediaType == ""
+}
+
+// sent описывает отправленное сообщение поста, чтобы его можно было потом изменить
+func (r *Renderer) sent(chatID int64, messageID int, textKind string, opts sendOptions, exact bool) database.ChannelPost {
+	return database.ChannelPost{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		TextKind:    textKind,
+		HasKeyboard: opts.markup != nil,
+		Exact:       exact,
+	}
+}
+
+// postText собирает текст или подпись поста. Текстовые предложения публикуются с префиксом
+// из каталога строк, к подписям медиа он не добавляется.
+func (r *Renderer) postText(bot *telego.Bot, message database.Message, limit int) string {
+	body := message.MessageText
+	if isTextMessage(message) {
+		body = r.i18n.Channel().T("publish_text_prefix", body)
+	}
+	return r.decorate(bot, message, body, limit)
+}
+
+// messageText собирает текст поста, который отправляется отдельным сообщением. Если текст
+// не помещается в сообщение даже без оформления, он обрезается: иначе Telegram отклонит пост.
+func (r *Renderer) messageText(bot *telego.Bot, message database.Message) string {
+	return truncateText(r.postText(bot, message, maxTextLength), maxTextLength)
+}
+
+// EditText заменяет текст опубликованного поста на message.MessageText. Текст собирается
+// так же, как при публикации; у точных копий подставляется без оформления.
+func (r *Renderer) EditText(bot *telego.Bot, message database.Message) error {
+	message = r.withAttribution(message)
+	markup := r.keyboard(bot, message)
+
+	edited := false
+	for _, post := range message.Posts {
+		if post.TextKind == "" {
+			continue
+		}
+
+		var keyboard *telego.InlineKeyboardMarkup
+		if post.HasKeyboard {
+			keyboard = markup
+		}
+
+		limit := maxTextLength
+		if post.TextKind == postCaption {
+			limit = maxCaptionLength
+		}
+		text := message.MessageText
+		if !post.Exact {
+			text = r.postText(bot, message, limit)
+		}
+		if tu.UTF16TextLen(text) > limit {
+			return errPostTooLong
+		}
+
+		var err error
+		if post.TextKind == postCaption {
+			_, err = bot.EditMessageCaption(&telego.EditMessageCaptionParams{
+				ChatID:      tu.ID(post.ChatID),
+				MessageID:   post.MessageID,
+				Caption:     text,
+				ReplyMarkup: keyboard,
+			})
+		} else {
+			_, err = bot.EditMessageText(&telego.EditMessageTextParams{
+				ChatID:      tu.ID(post.ChatID),
+				MessageID:   post.MessageID,
+				Text:        text,
+				ReplyMarkup: keyboard,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		edited = true
+	}
+
+	if !edited {
+		return errNoPostText
+	}
+	return nil
+}
+
+var (
+	// errNoPostText - в посте нет текста, который можно изменить (например, только стикер)
+	errNoPostText  = errors.New("post has no text")
+	errPostTooLong = errors.New("post text is too long")
+)
+
+// keyboard собирает кнопки поста: добавленные модератором ссылки и, если включено,
+// кнопку «Предложить пост»
+func (r *Renderer) keyboard(bot *telego.Bot, message database.Message) *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for _, button := range message.Buttons {
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(button.Text).WithURL(button.URL)))
+	}
+
+	if r.settings.Bool(settings.KeySuggestButton) {
+		if link := r.suggestLink(bot); link != "" {
+			text := r.i18n.Channel().T("btn_suggest_post")
+			rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton(text).WithURL(link)))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return tu.InlineKeyboard(rows...)
+}
+
+func (r *Renderer) hasTemplates() bool {
+	return r.settings.Get(settings.KeyPostHeader) != "" ||
+		r.settings.Get(settings.KeyPostFooter) != "" ||
+		r.settings.Get(settings.KeyPostSignature) != ""
+}
+
+// decorate оформляет текст поста шапкой, подвалом и подписью владельца. Если результат
+// не помещается в limit, по очереди убираются подпись, подвал и шапка: текст предложения
+// важнее оформления.
+func (r *Renderer) decorate(bot *telego.Bot, message database.Message, body string, limit int) string {
+	if !r.hasTemplates() {
+		return body
+	}
+
+	replacer := r.placeholders(bot, message)
+	header := replacer.Replace(r.settings.Get(settings.KeyPostHeader))
+	footer := replacer.Replace(r.settings.Get(settings.KeyPostFooter))
+	signature := replacer.Replace(r.settings.Get(settings.KeyPostSignature))
+
+	variants := [][]string{
+		{header, body, footer, signature},
+		{header, body, footer},
+		{header, body},
+	}
+	for _, parts := range variants {
+		text := joinNonEmpty(parts)
+		if tu.UTF16TextLen(text) <= limit {
+			return text
+		}
+	}
+	// Не помещается даже сам текст: подпись медиа тогда уходит отдельным сообщением,
+	// а сообщение обрезает messageText
+	return body
+}
+
+func (r *Renderer) placeholders(bot *telego.Bot, message database.Message) *strings.Replacer {
+	// У черновика, который ещё не сохранён, номера нет
+	number := "…"
+	if message.ID != 0 {
+		number = strconv.FormatUint(uint64(message.ID), 10)
+	}
+
+	return strings.NewReplacer(
+		"{number}", number,
+		// Подстановка убрана, пока у предложений нет категорий; в уже сохранённых шаблонах она пустая
+		"{category}", "",
+		"{date}", time.Now().Format("02.01.2006"),
+		"{channel_link}", r.channelLink(bot),
+		"{suggest_link}", r.suggestLink(bot),
+	)
+}
+
+// channelLink возвращает ссылку на канал публикации
+func (r *Renderer) channelLink(bot *telego.Bot) string {
+	return r.chatLink(bot, r.settings.ChannelID())
+}
+
+// PostLink возвращает ссылку на опубликованное сообщение поста
+func (r *Renderer) PostLink(bot *telego.Bot, post database.ChannelPost) string {
+	link := r.chatLink(bot, post.ChatID)
+	if link == "" {
+		return ""
+	}
+	return link + "/" + strconv.Itoa(post.MessageID)
+}
+
+// chatLink возвращает ссылку на канал: публичную по username или внутреннюю t.me/c/
+func (r *Renderer) chatLink(bot *telego.Bot, chatID int64) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if link, ok := r.links[chatID]; ok {
+		return link
+	}
+
+	chat, err := bot.GetChat(&telego.GetChatParams{ChatID: tu.ID(chatID)})
+	if err != nil {
+		slog.Warn("Ошибка получения информации о канале", "error", err)
+		return ""
+	}
+
+	link := "https://t.me/c/" + strings.TrimPrefix(strconv.FormatInt(chatID, 10), "-100")
+	if chat.Username != "" {
+		link = "https://t.me/" + chat.Username
+	}
+	r.links[chatID] = link
+	return link
+}
+
+// suggestLink возвращает ссылку, открывающую диалог с ботом
+func (r *Renderer) suggestLink(bot *telego.Bot) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.botUsername == "" {
+		me, err := bot.GetMe()
+		if err != nil {
+			slog.Warn("Ошибка получения информации о боте", "error", err)
+			return ""
+		}
+		r.botUsername = me.Username
+	}
+	return "https://t.me/" + r.botUsername + "?start=suggest"
+}
+
+// truncateText обрезает текст до limit единиц UTF-16, которыми Telegram измеряет длину
+func truncateText(text string, limit int) string {
+	if tu.UTF16TextLen(text) <= limit {
+		return text
+	}
+
+	const ellipsis = "…"
+	length := 0
+	for i, char := range text {
+		size := 1
+		if char >= 0x10000 {
+			size = 2
+		}
+		if length+size > limit-tu.UTF16TextLen(ellipsis) {
+			return text[:i] + ellipsis
+		}
+		length += size
+	}
+	return text
+}
+
+func joinNonEmpty(parts []string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+// Stage копирует исходное сообщение автора в служебный чат, чтобы затем показывать
+// и публиковать предложение точной копией. Составные предложения не копируются:
+// альбом из отдельных копий собрать нельзя.
+//
+// Без служебного чата пост собирается заново, а не копируется из диалога с автором:
+// для этого пришлось бы хранить ID чата автора открыто, хотя база знает отправителя
+// только по анонимной ссылке, а автор может удалить сообщение до публикации.
+func (r *Renderer) Stage(bot *telego.Bot, fromChatID int64, message *database.Message) {
+	message.StagingChatID = 0
+	message.StagingMessageID = 0
+
+	stagingChatID := r.settings.Int(settings.KeyStagingChatID)
+	if stagingChatID == 0 || len(message.Parts) > 0 {
+		return
+	}
+
+	copied, err := bot.CopyMessage(tu.CopyMessage(
+		tu.ID(stagingChatID),
+		tu.ID(fromChatID),
+		message.MessageID,
+	).WithDisableNotification())
+	if err != nil {
+		slog.Warn("Ошибка копирования предложения в служебный чат", "error", err)
+		return
+	}
+
+	message.StagingChatID = stagingChatID
+	message.StagingMessageID = copied.MessageID
+}
+
+// Unstage удаляет копию предложения из служебного чата
+func (r *Renderer) Unstage(bot *telego.Bot, message database.Message) {
+	if message.StagingMessageID == 0 {
+		return
+	}
+
+	err := bot.DeleteMessage(tu.Delete(tu.ID(message.StagingChatID), message.StagingMessageID))
+	if err != nil {
+		slog.Warn("Ошибка удаления копии предложения из служебного чата", "proposal", message.ID, "error", err)
+	}
+}
+
+// copyStaged воспроизводит предложение копией из служебного чата. Возвращает false, если
+// копии нет или скопировать её не удалось - тогда сообщение собирается заново.
+func (r *Renderer) copyStaged(bot *telego.Bot, chatID int64, message database.Message, opts sendOptions) (int, bool) {
+	if message.StagingMessageID == 0 {
+		return 0, false
+	}
+
+	params := tu.CopyMessage(tu.ID(chatID), tu.ID(message.StagingChatID), message.StagingMessageID)
+	params.DisableNotification = opts.silent
+	params.ProtectContent = opts.protect
+	params.ReplyMarkup = opts.replyMarkup()
+
+	copied, err := bot.CopyMessage(params)
+	if err != nil {
+		slog.Warn("Ошибка копирования предложения, используется пересборка", "proposal", message.ID, "error", err)
+		return 0, false
+	}
+	return copied.MessageID, true
+}
+
+// withAttribution добавляет к посту источник пересланного сообщения, если этого
+// требует политика для пересланных сообщений
+func (r *Renderer) withAttribution(message database.Message) database.Message {
+	if message.ForwardSource == "" || r.settings.Get(settings.KeyForwardPolicy) != settings.ForwardAttribute {
+		return message
+	}
+
+	via := r.i18n.Channel().T("publish_via", message.ForwardSource)
+	message.MessageText = strings.TrimSpace(message.MessageText + "\n\n" + via)
+	return message
+}
+
+// sendComposite отправляет составное предложение как один пост: фото и видео собираются
+// в альбом, остальные вложения идут отдельными сообщениями, а общий текст становится
+// подписью к первому подходящему вложению или отдельным сообщением
+func (r *Renderer) sendComposite(bot *telego.Bot, chatID int64, message database.Message, opts sendOptions) ([]database.ChannelPost, error) {
+	caption := r.postText(bot, message, maxCaptionLength)
+	// Длинный текст не помещается в подпись и отправляется отдельным сообщением
+	textSent := caption == ""
+	fitsCaption := tu.UTF16TextLen(caption) <= maxCaptionLength
+
+	var albumParts, others []database.MessagePart
+	for _, part := range message.Parts {
+		switch {
+		case part.MediaType == "photo" || part.MediaType == "video":
+			albumParts = append(albumParts, part)
+		case part.MediaFileID != "":
+			others = append(others, part)
+		}
+	}
+
+	// Альбом из одного элемента Telegram не принимает
+	if len(albumParts) == 1 {
+		others = append(albumParts, others...)
+		albumParts = nil
+	}
+
+	album := make([]telego.InputMedia, 0, len(albumParts))
+	for _, part := range albumParts {
+		if part.MediaType == "photo" {
+			album = append(album, tu.MediaPhoto(tu.FileFromID(part.MediaFileID)))
+		} else {
+			album = append(album, tu.MediaVideo(tu.FileFromID(part.MediaFileID)))
+		}
+	}
+
+	var steps []postStep
+	for _, group := range splitAlbum(album) {
+		group := group
+		groupCaption := false
+		if !textSent && fitsCaption {
+			setAlbumCaption(group[0], caption)
+			textSent = true
+			groupCaption = true
+		}
+
+		steps = append(steps, postStep{album: true, send: func(opts sendOptions) ([]database.ChannelPost, error) {
+			params := tu.MediaGroup(tu.ID(chatID), group...)
+			params.DisableNotification = opts.silent
+			params.ProtectContent = opts.protect
+
+			sent, err := bot.SendMediaGroup(params)
+			posts := make([]database.ChannelPost, 0, len(sent))
+			for i, item := range sent {
+				kind := ""
+				if i == 0 && groupCaption {
+					kind = postCaption
+				}
+				posts = append(posts, r.sent(chatID, item.MessageID, kind, opts, false))
+			}
+			return posts, err
+		}})
+	}
+
+	for _, part := range others {
+		part := part
+		partCaption := ""
+		if !textSent && fitsCaption && supportsCaption(part.MediaType) {
+			partCaption = caption
+			textSent = true
+		}
+		steps = append(steps, postStep{send: func(opts sendOptions) ([]database.ChannelPost, error) {
+			id, err := r.sendMedia(bot, chatID, part.MediaType, part.MediaFileID, partCaption, opts)
+			kind := ""
+			if partCaption != "" {
+				kind = postCaption
+			}
+			return []database.ChannelPost{r.sent(chatID, id, kind, opts, false)}, err
+		}})
+	}
+
+	if !textSent {
+		text := r.messageText(bot, message)
+		steps = append(steps, postStep{send: func(opts sendOptions) ([]database.ChannelPost, error) {
+			id, err := r.sendText(bot, chatID, text, opts)
+			return []database.ChannelPost{r.sent(chatID, id, postText, opts, false)}, err
+		}})
+	}
+
+	// К альбому нельзя прикрепить кнопки, поэтому они отправляются отдельным сообщением
+	if opts.markup != nil && (len(steps) == 0 || steps[len(steps)-1].album) {
+		buttonsText := r.i18n.Channel().T("post_buttons_text")
+		steps = append(steps, postStep{send: func(opts sendOptions) ([]database.ChannelPost, error) {
+			id, err := r.sendText(bot, chatID, buttonsText, opts)
+			return []database.ChannelPost{r.sent(chatID, id, "", opts, false)}, err
+		}})
+	}
+
+	// Кнопки прикрепляются только к последнему сообщению поста
+	plain := opts
+	plain.markup = nil
+
+	var posts []database.ChannelPost
+	for i, step := range steps {
+		stepOpts := plain
+		if i == len(steps)-1 {
+			stepOpts = opts
+		}
+		sent, err := step.send(stepOpts)
+		posts = append(posts, sent...)
+		if err != nil {
+			return posts, err
+		}
+	}
+	return posts, nil
+}
+
+// splitAlbum делит альбом на группы не больше maxAlbumSize. Группа из одного элемента
+// Telegram не принимает, поэтому элементы распределяются по группам поровну:
+// 11 элементов уходят как 6 и 5, а не 10 и 1.
+func splitAlbum(album []telego.InputMedia) [][]telego.InputMedia {
+	if len(album) == 0 {
+		return nil
+	}
+
+	count := (len(album) + maxAlbumSize - 1) / maxAlbumSize
+	groups := make([][]telego.InputMedia, 0, count)
+	start := 0
+	for i := 0; i < count; i++ {
+		size := len(album) / count
+		if i < len(album)%count {
+			size++
+		}
+		groups = append(groups, album[start:start+size])
+		start += size
+	}
+	return groups
+}
+
+// postStep - одна отправка составного поста
+type postStep struct {
+	album bool
+	send  func(opts sendOptions) ([]database.ChannelPost, error)
+}
+
+func setAlbumCaption(media telego.InputMedia, caption string) {
+	switch item := media.(type) {
+	case *telego.InputMediaPhoto:
+		item.Caption = caption
+	case *telego.InputMediaVideo:
+		item.Caption = caption
+	}
+}
+
+func supportsCaption(mediaType string) bool {
+	switch mediaType {
+	case "photo", "video", "document", "audio", "voice":
+		return true
+	}
+	return false
+}
+
+func (r *Renderer) sendText(bot *telego.Bot, chatID int64, text string, opts sendOptions) (int, error) {
+	params := tu.Message(tu.ID(chatID), text)
+	params.DisableNotification = opts.silent
+	params.ProtectContent = opts.protect
+	params.ReplyMarkup = opts.replyMarkup()
+
+	sent, err := bot.SendMessage(params)
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// sendMedia отправляет одно вложение. Это единственное место, где перечисляются
+// поддерживаемые типы медиа: новые типы добавляются сюда и в GetMediaInfo.
+func (r *Renderer) sendMedia(bot *telego.Bot, chatID int64, mediaType, fileID, caption string, opts sendOptions) (int, error) {
+	var sent *telego.Message
+	var err error
+	file := tu.FileFromID(fileID)
+
+	switch mediaType {
+	case "photo":
+		params := tu.Photo(tu.ID(chatID), file).WithCaption(caption)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendPhoto(params)
+	case "video":
+		params := tu.Video(tu.ID(chatID), file).WithCaption(caption)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendVideo(params)
+	case "document":
+		params := tu.Document(tu.ID(chatID), file).WithCaption(caption)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendDocument(params)
+	case "audio":
+		params := tu.Audio(tu.ID(chatID), file).WithCaption(caption)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendAudio(params)
+	case "voice":
+		params := tu.Voice(tu.ID(chatID), file).WithCaption(caption)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendVoice(params)
+	case "video_note":
+		params := tu.VideoNote(tu.ID(chatID), file)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendVideoNote(params)
+	case "sticker":
+		params := tu.Sticker(tu.ID(chatID), file)
+		params.DisableNotification, params.ProtectContent = opts.silent, opts.protect
+		params.ReplyMarkup = opts.replyMarkup()
+		sent, err = bot.SendSticker(params)
+	default:
+		return r.sendText(bot, chatID, caption, opts)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
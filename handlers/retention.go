@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// RetentionHandler очищает старые данные предложений по срокам хранения из настроек
+type RetentionHandler struct {
+	db       database.Storage
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	ownerID  int64
+}
+
+func NewRetentionHandler(db database.Storage, store *settings.Store, localizer *i18n.Localizer, ownerID int64) *RetentionHandler {
+	return &RetentionHandler{
+		db:       db,
+		settings: store,
+		i18n:     localizer,
+		ownerID:  ownerID,
+	}
+}
+
+// Purge применяет сроки хранения, запускается периодически. Копии очищенных предложений
+// удаляются и из служебного чата; чтобы удалять сообщения старше 48 часов, бот должен
+// быть там администратором.
+func (r *RetentionHandler) Purge(bot *telego.Bot) {
+	report, err := r.db.Purge(r.policy(), time.Now(), false)
+	if err != nil {
+		slog.Error("Ошибка очистки устаревших данных", "error", err)
+		return
+	}
+
+	for _, staged := range report.Staged {
+		if err := bot.DeleteMessage(tu.Delete(tu.ID(staged.ChatID), staged.MessageID)); err != nil {
+			slog.Warn("Ошибка удаления копии предложения из служебного чата", "proposal", staged.ProposalID, "error", err)
+		}
+	}
+
+	if !report.Empty() {
+		slog.Info("🧹 Очистка данных",
+			"rejected_wiped", report.RejectedWiped,
+			"published_anonymised", report.PublishedAnonymised,
+			"senders_forgotten", report.SendersForgotten,
+			"staged_copies", len(report.Staged),
+		)
+	}
+}
+
+// HandlePurgeCommand показывает владельцу, что удалит ближайшая очистка, ничего не меняя
+func (r *RetentionHandler) HandlePurgeCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := r.i18n.For(msg.From)
+
+	if msg.From.ID != r.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	policy := r.policy()
+	report, err := r.db.Purge(policy, time.Now(), true)
+	if err != nil {
+		slog.Error("Ошибка пробной очистки данных", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("purge_error"),
+		))
+		return
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("purge_report",
+			retentionDays(tr, policy.RejectedDays), report.RejectedWiped,
+			retentionDays(tr, policy.PublishedDays), report.PublishedAnonymised,
+			retentionDays(tr, policy.SenderDays), report.SendersForgotten,
+		),
+	))
+}
+
+func (r *RetentionHandler) policy() database.RetentionPolicy {
+	return database.RetentionPolicy{
+		RejectedDays:  int(r.settings.Int(settings.KeyRetentionRejected)),
+		PublishedDays: int(r.settings.Int(settings.KeyRetentionPublished)),
+		SenderDays:    int(r.settings.Int(settings.KeyRetentionSender)),
+	}
+}
+
+func retentionDays(tr i18n.Printer, days int) string {
+	if days <= 0 {
+		return tr.T("purge_forever")
+	}
+	return tr.T("purge_days", days)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const searchPageSize = 5
+
+// HandleSearchCommand ищет предложения по тексту с фильтрами:
+// /search [status:<статус>] [type:<тип>] [from:ГГГГ-ММ-ДД] [to:ГГГГ-ММ-ДД] <текст>
+func (m *ModerationHandler) HandleSearchCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := m.i18n.For(msg.From)
+
+	if !m.db.IsAdmin(msg.From.ID) && msg.From.ID != m.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	_, args := tu.ParseCommand(msg.Text)
+	filter, ok := parseSearchArgs(args)
+	if !ok {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("search_usage"),
+		))
+		return
+	}
+
+	// Фильтр запоминается, чтобы кнопки страниц не переносили запрос в callback data
+	m.searchMu.Lock()
+	m.searches[msg.From.ID] = filter
+	m.searchMu.Unlock()
+
+	text, keyboard, err := m.searchPage(filter, 0, tr)
+	if err != nil {
+		slog.Error("Ошибка поиска предложений", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("search_error"),
+		))
+		return
+	}
+
+	params := tu.Message(tu.ID(msg.Chat.ID), text)
+	if keyboard != nil {
+		params = params.WithReplyMarkup(keyboard)
+	}
+	bot.SendMessage(params)
+}
+
+// HandleSearchCallback листает результаты поиска и открывает найденные предложения
+func (m *ModerationHandler) HandleSearchCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := m.i18n.For(&callback.From)
+
+	if !m.db.IsAdmin(callback.From.ID) && callback.From.ID != m.ownerID {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("callback_no_access")))
+		return
+	}
+
+	chatID := callback.Message.Chat.ID
+	var proposalID, page uint
+
+	switch {
+	case scanProposal(callback.Data, "search_open_%d", &proposalID):
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+
+		message, err := m.db.GetMessageByID(proposalID)
+		if err != nil {
+			bot.SendMessage(tu.Message(
+				tu.ID(chatID),
+				tr.T("proposal_not_found"),
+			))
+			return
+		}
+		if message.Status == "pending" {
+			m.SendMessageForModeration(bot, chatID, message, tr)
+		} else {
+			m.showPost(bot, chatID, proposalID, tr)
+		}
+
+	case scanProposal(callback.Data, "search_page_%d", &page):
+		m.searchMu.Lock()
+		filter, ok := m.searches[callback.From.ID]
+		m.searchMu.Unlock()
+		if !ok {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(
+				callback.ID,
+			).WithText(tr.T("search_outdated")))
+			return
+		}
+
+		text, keyboard, err := m.searchPage(filter, int(page), tr)
+		if err != nil {
+			slog.Error("Ошибка поиска предложений", "error", err)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(
+				callback.ID,
+			).WithText(tr.T("search_error")))
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:      tu.ID(chatID),
+			MessageID:   callback.Message.MessageID,
+			Text:        text,
+			ReplyMarkup: keyboard,
+		})
+	}
+}
+
+// searchPage выводит страницу результатов с кнопками открытия и листания
+func (m *ModerationHandler) searchPage(filter database.SearchFilter, page int, tr i18n.Printer) (string, *telego.InlineKeyboardMarkup, error) {
+	messages, total, err := m.db.SearchMessages(filter, searchPageSize, page*searchPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+	if total == 0 || len(messages) == 0 {
+		return tr.T("search_empty"), nil, nil
+	}
+
+	pages := int((total + searchPageSize - 1) / searchPageSize)
+
+	var text strings.Builder
+	text.WriteString(tr.T("search_results", total, page+1, pages))
+
+	var open []telego.InlineKeyboardButton
+	for _, message := range messages {
+		text.WriteString(fmt.Sprintf("\n\n#%d · %s · %s\n%s",
+			message.ID,
+			tr.T("status_"+message.Status),
+			message.CreatedAt.Format("02.01.2006"),
+			snippet(message.MessageText, 120),
+		))
+		open = append(open, tu.InlineKeyboardButton(fmt.Sprintf("#%d", message.ID)).WithCallbackData(fmt.Sprintf("search_open_%d", message.ID)))
+	}
+
+	rows := [][]telego.InlineKeyboardButton{open}
+	var navigation []telego.InlineKeyboardButton
+	if page > 0 {
+		navigation = append(navigation, tu.InlineKeyboardButton(tr.T("btn_search_prev")).WithCallbackData(fmt.Sprintf("search_page_%d", page-1)))
+	}
+	if page+1 < pages {
+		navigation = append(navigation, tu.InlineKeyboardButton(tr.T("btn_search_next")).WithCallbackData(fmt.Sprintf("search_page_%d", page+1)))
+	}
+	if len(navigation) > 0 {
+		rows = append(rows, navigation)
+	}
+
+	return text.String(), tu.InlineKeyboard(rows...), nil
+}
+
+// parseSearchArgs отделяет фильтры вида ключ:значение от текста запроса.
+// Дата "to" включается в выборку целиком.
+func parseSearchArgs(args []string) (database.SearchFilter, bool) {
+	var filter database.SearchFilter
+	var words []string
+
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, ":")
+		if !found || value == "" {
+			words = append(words, arg)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "status":
+			filter.Status = strings.ToLower(value)
+		case "type":
+			filter.MediaType = strings.ToLower(value)
+		case "from":
+			date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return filter, false
+			}
+			filter.Since = date
+		case "to":
+			date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return filter, false
+			}
+			filter.Until = date.AddDate(0, 0, 1)
+		default:
+			words = append(words, arg)
+		}
+	}
+
+	filter.Query = strings.Join(words, " ")
+	if filter.Query == "" && filter.Status == "" && filter.MediaType == "" && filter.Since.IsZero() && filter.Until.IsZero() {
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const settingsInputPrefix = "settings:"
+
+type SettingsHandler struct {
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	inputs   *InputWaiter
+	ownerID  int64
+}
+
+func NewSettingsHandler(store *settings.Store, localizer *i18n.Localizer, inputs *InputWaiter, ownerID int64) *SettingsHandler {
+	return &SettingsHandler{
+		settings: store,
+		i18n:     localizer,
+		inputs:   inputs,
+		ownerID:  ownerID,
+	}
+}
+
+func (s *SettingsHandler) HandleSettingsCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := s.i18n.For(msg.From)
+
+	if msg.From.ID != s.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("settings_owner_only"),
+		))
+		return
+	}
+
+	s.ShowSettings(bot, msg.Chat.ID, tr)
+}
+
+func (s *SettingsHandler) ShowSettings(bot *telego.Bot, chatID int64, tr i18n.Printer) {
+	text := tr.T("settings_title")
+	var rows [][]telego.InlineKeyboardButton
+
+	for _, def := range settings.Definitions() {
+		title := tr.T("setting_" + def.Key)
+		value := s.settings.Get(def.Key)
+		marker := ""
+		if s.settings.IsDefault(def.Key) {
+			marker = tr.T("settings_default_marker")
+		}
+		text += "• " + title + ": " + s.formatValue(def, value, tr) + marker + "\n"
+
+		button := tu.InlineKeyboardButton("✏️ " + title).WithCallbackData("settings_edit_" + def.Key)
+		if def.Kind == settings.KindBool || def.Kind == settings.KindChoice {
+			button = tu.InlineKeyboardButton("🔁 " + title).WithCallbackData("settings_toggle_" + def.Key)
+		}
+		rows = append(rows, tu.InlineKeyboardRow(button))
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(chatID),
+		text,
+	).WithReplyMarkup(tu.InlineKeyboard(rows...)))
+}
+
+func (s *SettingsHandler) formatValue(def settings.Definition, value string, tr i18n.Printer) string {
+	switch def.Kind {
+	case settings.KindBool:
+		if enabled, _ := strconv.ParseBool(value); enabled {
+			return tr.T("settings_on")
+		}
+		return tr.T("settings_off")
+	case settings.KindLanguage:
+		return i18n.LanguageName(value)
+	case settings.KindChoice:
+		return tr.T(def.Key + "_" + value)
+	case settings.KindString, settings.KindTemplate:
+		if value == "" {
+			return tr.T("settings_empty_value")
+		}
+		value = strings.ReplaceAll(value, "\n", " ")
+		if runes := []rune(value); len(runes) > 40 {
+			return "«" + string(runes[:40]) + "…»"
+		}
+		return "«" + value + "»"
+	default:
+		return value
+	}
+}
+
+func (s *SettingsHandler) HandleCallback(bot *telego.Bot, update telego.Update) {
+	callback := update.CallbackQuery
+	if callback == nil {
+		return
+	}
+	tr := s.i18n.For(&callback.From)
+
+	if callback.From.ID != s.ownerID {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("callback_no_access")))
+		return
+	}
+
+	chatID := callback.Message.Chat.ID
+	data := callback.Data
+
+	switch {
+	case strings.HasPrefix(data, "settings_toggle_"):
+		key := strings.TrimPrefix(data, "settings_toggle_")
+		def, ok := settings.Lookup(key)
+		if !ok {
+			return
+		}
+
+		value := strconv.FormatBool(!s.settings.Bool(key))
+		if def.Kind == settings.KindChoice {
+			value = nextOption(def.Options, s.settings.Get(key))
+		}
+		if err := s.settings.Set(key, value); err != nil {
+			slog.Error("Ошибка сохранения настройки", "key", key, "error", err)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(
+				callback.ID,
+			).WithText(tr.T("settings_save_error")))
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("settings_toggled")))
+
+		bot.DeleteMessage(&telego.DeleteMessageParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: callback.Message.MessageID,
+		})
+		s.ShowSettings(bot, chatID, tr)
+
+	case strings.HasPrefix(data, "settings_edit_"):
+		key := strings.TrimPrefix(data, "settings_edit_")
+		def, ok := settings.Lookup(key)
+		if !ok {
+			return
+		}
+
+		s.inputs.Wait(callback.From.ID, settingsInputPrefix+key)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+
+		keyboard := tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(tr.T("btn_reset_default")).WithCallbackData("settings_reset_" + key),
+			),
+		)
+
+		prompt := tr.T("settings_edit_prompt", tr.T("setting_"+def.Key), s.settings.Get(key))
+		if def.Kind == settings.KindTemplate {
+			prompt += "\n\n" + tr.T("settings_template_hint", strings.Join(templatePlaceholders, " "))
+		}
+
+		bot.SendMessage(tu.Message(
+			tu.ID(chatID),
+			prompt,
+		).WithReplyMarkup(keyboard))
+
+	case strings.HasPrefix(data, "settings_reset_"):
+		key := strings.TrimPrefix(data, "settings_reset_")
+		if _, ok := settings.Lookup(key); !ok {
+			return
+		}
+
+		s.inputs.Cancel(callback.From.ID)
+		if err := s.settings.Reset(key); err != nil {
+			slog.Error("Ошибка сброса настройки", "key", key, "error", err)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(
+				callback.ID,
+			).WithText(tr.T("settings_reset_error")))
+			return
+		}
+
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("settings_reset_done")))
+		s.ShowSettings(bot, chatID, tr)
+	}
+}
+
+func (s *SettingsHandler) HandleSettingInput(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+
+	action, ok := s.inputs.Take(msg.From.ID)
+	if !ok || msg.From.ID != s.ownerID {
+		return
+	}
+	tr := s.i18n.For(msg.From)
+
+	key := strings.TrimPrefix(action, settingsInputPrefix)
+	def, ok := settings.Lookup(key)
+	if !ok {
+		return
+	}
+
+	value := msg.Text
+	if value == "" {
+		value = msg.Caption
+	}
+	value = strings.TrimSpace(value)
+	if def.Kind == settings.KindTemplate && value == "-" {
+		value = ""
+	}
+
+	if err := validateSetting(def, value, tr); err != nil {
+		s.inputs.Wait(msg.From.ID, action)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("settings_invalid", err.Error()),
+		))
+		return
+	}
+
+	if err := s.settings.Set(key, value); err != nil {
+		slog.Error("Ошибка сохранения настройки", "key", key, "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("settings_save_failed", err.Error()),
+		))
+		return
+	}
+
+	slog.Info("Настройка изменена владельцем", "key", key)
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		tr.T("settings_saved", tr.T("setting_"+def.Key)),
+	))
+	s.ShowSettings(bot, msg.Chat.ID, tr)
+}
+
+func validateSetting(def settings.Definition, value string, tr i18n.Printer) error {
+	if value == "" && def.Kind != settings.KindTemplate {
+		return errors.New(tr.T("validation_empty"))
+	}
+
+	switch def.Kind {
+	case settings.KindInt:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New(tr.T("validation_int"))
+		}
+		if def.Unsigned && number < 0 {
+			return errors.New(tr.T("validation_negative"))
+		}
+	case settings.KindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New(tr.T("validation_bool"))
+		}
+	case settings.KindLanguage:
+		if !i18n.IsSupported(value) {
+			return errors.New(tr.T("validation_lang", strings.Join(i18n.Languages(), ", ")))
+		}
+	case settings.KindChoice:
+		if !slices.Contains(def.Options, value) {
+			return errors.New(tr.T("validation_choice", strings.Join(def.Options, ", ")))
+		}
+	case settings.KindTemplate:
+		for _, placeholder := range placeholderPattern.FindAllString(value, -1) {
+			if !slices.Contains(templatePlaceholders, placeholder) {
+				return errors.New(tr.T("validation_placeholder", placeholder, strings.Join(templatePlaceholders, " ")))
+			}
+		}
+	}
+
+	return nil
+}
+
+// nextOption возвращает вариант, следующий за текущим (по кругу)
+func nextOption(options []string, current string) string {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// HandleTextCommand позволяет владельцу переопределить любую строку каталога: /text <язык> <ключ> [текст]
+func (s *SettingsHandler) HandleTextCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := s.i18n.For(msg.From)
+
+	if msg.From.ID != s.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_owner_only"),
+		))
+		return
+	}
+
+	// Текст может содержать переводы строк, поэтому делим только первые три поля
+	parts := splitArgs(msg.Text, 4)
+	languages := strings.Join(i18n.Languages(), ", ")
+
+	if len(parts) == 2 && parts[1] == "keys" {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_keys", strings.Join(i18n.Keys(), "\n")),
+		))
+		return
+	}
+
+	if len(parts) < 3 {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_usage", languages),
+		))
+		return
+	}
+
+	lang, key := parts[1], strings.TrimSpace(parts[2])
+	if !i18n.IsSupported(lang) {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_unknown_lang", languages),
+		))
+		return
+	}
+	if !i18n.HasKey(key) {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_unknown_key", key),
+		))
+		return
+	}
+
+	overrideKey := i18n.OverrideKey(lang, key)
+
+	if len(parts) == 3 {
+		marker := ""
+		if !s.settings.IsDefault(overrideKey) {
+			marker = tr.T("text_overridden")
+		}
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_current", key, lang, marker, s.i18n.Translate(lang, key)),
+		))
+		return
+	}
+
+	value := strings.TrimSpace(parts[3])
+	var err error
+	reply := tr.T("text_saved", key, lang)
+	if value == "-" {
+		err = s.settings.Reset(overrideKey)
+		reply = tr.T("text_reset", key, lang)
+	} else {
+		// Строки без подстановок выводятся без Sprintf, поэтому знак % в них допустим
+		expected := i18n.Verbs(i18n.Source(lang, key))
+		if len(expected) > 0 && !slices.Equal(i18n.Verbs(value), expected) {
+			bot.SendMessage(tu.Message(
+				tu.ID(msg.Chat.ID),
+				tr.T("text_bad_verbs", strings.Join(expected, " ")),
+			))
+			return
+		}
+		err = s.settings.Set(overrideKey, value)
+	}
+
+	if err != nil {
+		slog.Error("Ошибка сохранения текста", "key", overrideKey, "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("text_error", err.Error()),
+		))
+		return
+	}
+
+	slog.Info("Текст изменён владельцем", "key", overrideKey)
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		reply,
+	))
+}
+
+// splitArgs делит текст команды на n частей по пробельным символам, оставляя последнюю часть без изменений
+func splitArgs(text string, n int) []string {
+	var parts []string
+	rest := strings.TrimSpace(text)
+	for len(parts) < n-1 && rest != "" {
+		i := strings.IndexAny(rest, " \t\n")
+		if i < 0 {
+			break
+		}
+		parts = append(parts, rest[:i])
+		rest = strings.TrimLeft(rest[i:], " \t\n")
+	}
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
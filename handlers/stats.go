@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot/database"
+	"telegram-bot/i18n"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+type StatsHandler struct {
+	db       database.Storage
+	settings *settings.Store
+	i18n     *i18n.Localizer
+	ownerID  int64
+}
+
+func NewStatsHandler(db database.Storage, store *settings.Store, localizer *i18n.Localizer, ownerID int64) *StatsHandler {
+	return &StatsHandler{
+		db:       db,
+		settings: store,
+		i18n:     localizer,
+		ownerID:  ownerID,
+	}
+}
+
+// moderatorStats - показатели одного модератора по журналу решений
+type moderatorStats struct {
+	userID    int64
+	approved  int
+	rejected  int
+	day       int
+	week      int
+	month     int
+	durations []time.Duration
+}
+
+// HandleModStatsCommand показывает владельцу статистику работы модераторов
+func (s *StatsHandler) HandleModStatsCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := s.i18n.For(msg.From)
+
+	if msg.From.ID != s.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	text, err := s.moderatorReport(tr, time.Now())
+	if err != nil {
+		slog.Error("Ошибка получения статистики модераторов", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("stats_error"),
+		))
+		return
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		text,
+	))
+}
+
+// SendOwnerReport раз в report_days дней отправляет владельцу отчёт о работе модераторов
+func (s *StatsHandler) SendOwnerReport(bot *telego.Bot) {
+	days := s.settings.Int(settings.KeyReportDays)
+	if days <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if last, err := time.Parse(time.RFC3339, s.settings.Get(settings.KeyLastReportAt)); err == nil && now.Sub(last) < time.Duration(days)*24*time.Hour {
+		return
+	}
+
+	tr := s.i18n.ForUserID(s.ownerID)
+	text, err := s.moderatorReport(tr, now)
+	if err != nil {
+		slog.Error("Ошибка подготовки отчёта владельцу", "error", err)
+		return
+	}
+
+	if _, err := bot.SendMessage(tu.Message(
+		tu.ID(s.ownerID),
+		tr.T("stats_report_title", days)+"\n\n"+text,
+	)); err != nil {
+		slog.Warn("Ошибка отправки отчёта владельцу", "error", err)
+		return
+	}
+
+	if err := s.settings.Set(settings.KeyLastReportAt, now.Format(time.RFC3339)); err != nil {
+		slog.Error("Ошибка сохранения времени отчёта", "error", err)
+	}
+}
+
+// moderatorReport строит таблицу модераторов, отсортированную по активности за месяц
+func (s *StatsHandler) moderatorReport(tr i18n.Printer, now time.Time) (string, error) {
+	decisions, err := s.db.GetDecisions(time.Time{})
+	if err != nil {
+		return "", err
+	}
+	if len(decisions) == 0 {
+		return tr.T("modstats_empty"), nil
+	}
+
+	byModerator := make(map[int64]*moderatorStats)
+	for _, decision := range decisions {
+		stats, ok := byModerator[decision.ActorID]
+		if !ok {
+			stats = &moderatorStats{userID: decision.ActorID}
+			byModerator[decision.ActorID] = stats
+		}
+
+		if decision.Action == "approve" {
+			stats.approved++
+		} else {
+			stats.rejected++
+		}
+		stats.durations = append(stats.durations, decision.DecidedAt.Sub(decision.SubmittedAt))
+
+		age := now.Sub(decision.DecidedAt)
+		if age < 24*time.Hour {
+			stats.day++
+		}
+		if age < 7*24*time.Hour {
+			stats.week++
+		}
+		if age < 30*24*time.Hour {
+			stats.month++
+		}
+	}
+
+	moderators := make([]*moderatorStats, 0, len(byModerator))
+	for _, stats := range byModerator {
+		moderators = append(moderators, stats)
+	}
+	sort.Slice(moderators, func(i, j int) bool {
+		if moderators[i].month != moderators[j].month {
+			return moderators[i].month > moderators[j].month
+		}
+		return moderators[i].approved+moderators[i].rejected > moderators[j].approved+moderators[j].rejected
+	})
+
+	names := make(map[int64]string)
+	if admins, err := s.db.GetAdmins(); err == nil {
+		for _, admin := range admins {
+			names[admin.UserID] = admin.UserName
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("modstats_title"))
+	for i, stats := range moderators {
+		name := names[stats.userID]
+		if name == "" {
+			name = tr.T("modstats_unknown")
+		}
+		text.WriteString("\n\n" + fmt.Sprintf("%d. %s (ID %d)\n", i+1, name, stats.userID))
+		text.WriteString(tr.T("modstats_line",
+			stats.approved,
+			stats.rejected,
+			formatAge(tr, median(stats.durations)),
+			stats.day,
+			stats.week,
+			stats.month,
+		))
+	}
+	return text.String(), nil
+}
+
+// channelStats - сводка по предложениям за всю сохранённую историю
+type channelStats struct {
+	total    int64
+	days     []database.GroupCount
+	statuses []database.GroupCount
+	media    []database.GroupCount
+	avgWait  time.Duration
+	hours    [24]int64
+}
+
+// HandleStatsCommand показывает статистику предложений: /stats или /stats csv
+func (s *StatsHandler) HandleStatsCommand(bot *telego.Bot, update telego.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	tr := s.i18n.For(msg.From)
+
+	if !s.db.IsAdmin(msg.From.ID) && msg.From.ID != s.ownerID {
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("no_access"),
+		))
+		return
+	}
+
+	now := time.Now()
+	stats, err := s.channelStats()
+	if err != nil {
+		slog.Error("Ошибка получения статистики предложений", "error", err)
+		bot.SendMessage(tu.Message(
+			tu.ID(msg.Chat.ID),
+			tr.T("stats_error"),
+		))
+		return
+	}
+
+	bot.SendMessage(tu.Message(
+		tu.ID(msg.Chat.ID),
+		formatChannelStats(stats, tr, now),
+	))
+
+	_, args := tu.ParseCommand(msg.Text)
+	if len(args) == 1 && strings.EqualFold(args[0], "csv") {
+		if _, err := bot.SendDocument(tu.Document(
+			tu.ID(msg.Chat.ID),
+			tu.File(tu.NameReader(bytes.NewReader(statsCSV(stats, now)), "stats.csv")),
+		)); err != nil {
+			slog.Warn("Ошибка отправки CSV статистики", "error", err)
+		}
+	}
+}
+
+func (s *StatsHandler) channelStats() (channelStats, error) {
+	var stats channelStats
+	var err error
+
+	if stats.statuses, err = s.db.CountByStatus(time.Time{}); err != nil {
+		return stats, err
+	}
+	if stats.media, err = s.db.CountByMediaType(time.Time{}); err != nil {
+		return stats, err
+	}
+	if stats.days, err = s.db.CountByDay(time.Time{}); err != nil {
+		return stats, err
+	}
+	if stats.avgWait, err = s.db.AverageWait(time.Time{}); err != nil {
+		return stats, err
+	}
+
+	hours, err := s.db.CountByHour(time.Time{})
+	if err != nil {
+		return stats, err
+	}
+	for _, hour := range hours {
+		if h, err := strconv.Atoi(hour.Value); err == nil && h >= 0 && h < 24 {
+			stats.hours[h] = hour.Count
+		}
+	}
+
+	for _, status := range stats.statuses {
+		stats.total += status.Count
+	}
+	return stats, nil
+}
+
+// Сколько последних дней и недель показывается в статистике по отдельности
+const (
+	statsDays  = 7
+	statsWeeks = 4
+)
+
+func formatChannelStats(stats channelStats, tr i18n.Printer, now time.Time) string {
+	if stats.total == 0 {
+		return tr.T("stats_empty")
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T("stats_title", stats.total))
+
+	daily := dailyCounts(stats.days)
+	text.WriteString("\n\n" + tr.T("stats_days"))
+	for i := 0; i < statsDays; i++ {
+		day := now.AddDate(0, 0, -i)
+		text.WriteString(fmt.Sprintf("\n• %s: %d", day.Format("02.01"), daily[day.Format("2006-01-02")]))
+	}
+
+	text.WriteString("\n\n" + tr.T("stats_weeks"))
+	for i := 0; i < statsWeeks; i++ {
+		end := now.AddDate(0, 0, -7*i)
+		start := end.AddDate(0, 0, -6)
+		text.WriteString(fmt.Sprintf("\n• %s-%s: %d", start.Format("02.01"), end.Format("02.01"), weekCount(daily, end)))
+	}
+
+	text.WriteString("\n\n" + tr.T("stats_statuses"))
+	for _, status := range stats.statuses {
+		text.WriteString(fmt.Sprintf("\n• %s: %d (%d%%)", tr.T("status_"+status.Value), status.Count, status.Count*100/stats.total))
+	}
+
+	text.WriteString("\n\n" + tr.T("stats_media"))
+	for _, media := range stats.media {
+		text.WriteString(fmt.Sprintf("\n• %s: %d", media.Value, media.Count))
+	}
+
+	text.WriteString("\n\n" + tr.T("stats_wait", formatAge(tr, stats.avgWait)))
+
+	text.WriteString("\n\n" + tr.T("stats_hours"))
+	for _, hour := range busiestHours(stats.hours, 3) {
+		text.WriteString(fmt.Sprintf("\n• %02d:00-%02d:59: %d", hour, hour, stats.hours[hour]))
+	}
+	return text.String()
+}
+
+// dailyCounts превращает ряд по дням в таблицу "ГГГГ-ММ-ДД" -> число предложений
+func dailyCounts(days []database.GroupCount) map[string]int64 {
+	daily := make(map[string]int64, len(days))
+	for _, day := range days {
+		daily[day.Value] = day.Count
+	}
+	return daily
+}
+
+// weekCount суммирует предложения за семь дней, заканчивающихся днём end
+func weekCount(daily map[string]int64, end time.Time) int64 {
+	var count int64
+	for i := 0; i < 7; i++ {
+		count += daily[end.AddDate(0, 0, -i).Format("2006-01-02")]
+	}
+	return count
+}
+
+// busiestHours возвращает до limit часов с наибольшим числом предложений
+func busiestHours(hours [24]int64, limit int) []int {
+	order := make([]int, 0, 24)
+	for hour, count := range hours {
+		if count > 0 {
+			order = append(order, hour)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return hours[order[i]] > hours[order[j]] })
+	return order[:min(limit, len(order))]
+}
+
+// statsCSV выгружает статистику в формате "metric,key,value". Ряд по дням выгружается
+// за всю историю, недели - последние statsWeeks, как в сообщении.
+func statsCSV(stats channelStats, now time.Time) []byte {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"metric", "key", "value"})
+	writer.Write([]string{"received", "total", strconv.FormatInt(stats.total, 10)})
+	for _, day := range stats.days {
+		writer.Write([]string{"received_day", day.Value, strconv.FormatInt(day.Count, 10)})
+	}
+	daily := dailyCounts(stats.days)
+	for i := 0; i < statsWeeks; i++ {
+		end := now.AddDate(0, 0, -7*i)
+		writer.Write([]string{"received_week", end.AddDate(0, 0, -6).Format("2006-01-02"), strconv.FormatInt(weekCount(daily, end), 10)})
+	}
+	for _, status := range stats.statuses {
+		writer.Write([]string{"status", status.Value, strconv.FormatInt(status.Count, 10)})
+	}
+	for _, media := range stats.media {
+		writer.Write([]string{"media_type", media.Value, strconv.FormatInt(media.Count, 10)})
+	}
+	writer.Write([]string{"avg_wait_minutes", "", strconv.Itoa(int(stats.avgWait.Minutes()))})
+	for hour, count := range stats.hours {
+		writer.Write([]string{"hour", strconv.Itoa(hour), strconv.FormatInt(count, 10)})
+	}
+
+	writer.Flush()
+	return buffer.Bytes()
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"telegram-bot/i18n"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// scheduleApprove откладывает публикацию на delay. Пока пост не ушёл в канал, модератор
+// может отменить одобрение; время публикации хранится в базе и переживает перезапуск.
+func (m *ModerationHandler) scheduleApprove(bot *telego.Bot, chatID int64, proposalID uint, callback *telego.CallbackQuery, tr i18n.Printer, delay time.Duration) {
+	ok, err := m.db.ScheduleMessage(proposalID, time.Now().Add(delay))
+	if !m.checkClaim(bot, callback, tr, proposalID, ok, err) {
+		return
+	}
+	m.logAction(proposalID, callback.From.ID, "approve", "")
+
+	bot.AnswerCallbackQuery(tu.CallbackQuery(
+		callback.ID,
+	).WithText(tr.T("proposal_scheduled", int(delay.Seconds()))))
+
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    tu.ID(chatID),
+		MessageID: callback.Message.MessageID,
+		Text:      tr.T("proposal_scheduled_card", proposalID, int(delay.Seconds())),
+		ReplyMarkup: tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(tr.T("btn_undo")).WithCallbackData(fmt.Sprintf("undo_%d", proposalID)),
+			),
+		),
+	})
+
+	m.ShowProposals(bot, chatID, &callback.From)
+}
+
+// HandleUndo возвращает отложенное предложение в очередь. Если пост уже опубликован,
+// модератору предлагается удалить его из канала.
+func (m *ModerationHandler) HandleUndo(bot *telego.Bot, chatID int64, proposalID uint, callback *telego.CallbackQuery) {
+	tr := m.i18n.For(&callback.From)
+
+	ok, err := m.db.UpdateMessageStatus(proposalID, "scheduled", "pending")
+	if err != nil {
+		slog.Error("Ошибка отмены публикации предложения", "proposal", proposalID, "error", err)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_status_error")))
+		return
+	}
+
+	if ok {
+		m.logAction(proposalID, callback.From.ID, "undo", "")
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_undone")))
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: callback.Message.MessageID,
+			Text:      tr.T("proposal_undone_card", proposalID),
+		})
+		return
+	}
+
+	message, err := m.db.GetMessageByID(proposalID)
+	if err != nil || message.Status != "approved" {
+		bot.AnswerCallbackQuery(tu.CallbackQuery(
+			callback.ID,
+		).WithText(tr.T("proposal_already_decided")))
+		return
+	}
+
+	bot.AnswerCallbackQuery(tu.CallbackQuery(callback.ID))
+	bot.EditMessageText(&telego.EditMessageTextParams{
+		ChatID:    tu.ID(chatID),
+		MessageID: callback.Message.MessageID,
+		Text:      tr.T("proposal_undo_too_late", proposalID),
+		ReplyMarkup: tu.InlineKeyboard(
+			tu.InlineKeyboardRow(
+				tu.InlineKeyboardButton(tr.T("btn_post_delete")).WithCallbackData(fmt.Sprintf("post_delete_confirm_%d", proposalID)),
+			),
+		),
+	})
+}
+
+// PublishScheduled публикует предложения, время отложенной публикации которых наступило
+func (m *ModerationHandler) PublishScheduled(bot *telego.Bot) {
+	messages, err := m.db.GetDueMessages(time.Now())
+	if err != nil {
+		slog.Error("Ошибка получения отложенных предложений", "error", err)
+		return
+	}
+
+	for _, message := range messages {
+		// Отмена могла прийти одновременно с публикацией - выигрывает тот, кто первым сменил статус
+		ok, err := m.db.UpdateMessageStatus(message.ID, "scheduled", "approved")
+		if err != nil || !ok {
+			continue
+		}
+
+		if err := m.publish(bot, message); err != nil {
+			slog.Error("Ошибка отложенной публикации предложения", "proposal", message.ID, "error", err)
+			m.db.ReturnToPending(message.ID, "approved", message.SenderSeal)
+			continue
+		}
+		slog.Info("✅ Предложение опубликовано после ожидания отмены", "proposal", message.ID)
+	}
+}
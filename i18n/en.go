@@ -0,0 +1,326 @@
+package i18n
+
+var en = map[string]string{
+	"welcome": `🤖 Welcome to the anonymous suggestion box!
+
+Just send your suggestion, idea or message here and it will be reviewed anonymously by the moderators.
+
+Your identity stays hidden - moderators only see the content of your message.
+
+❓ What you can send:
+• Text suggestions
+• Photos
+• Documents
+• Videos
+• Video notes
+• Audio and voice messages
+• Stickers
+• Ideas and wishes
+
+Your suggestion will be reviewed soon!
+
+📋 Your suggestions: /my
+🎫 Status by code: /status <code>
+🌐 Change language: /language`,
+	"owner_panel": "👑 Owner panel\n\nThis is a bot for anonymous suggestions. Users send suggestions in private messages and you moderate them.\n\n" +
+		"Available commands:\n" +
+		"/addadmin <ID> - add an administrator\n" +
+		"/admins - list administrators\n" +
+		"/modstats - moderator statistics\n" +
+		"/export - database backup\n" +
+		"/purge - preview the retention cleanup\n" +
+		"/proposals - review suggestions\n" +
+		"/post <number> - manage a published post\n" +
+		"/search <text> - search suggestions\n" +
+		"/stats [csv] - suggestion statistics\n" +
+		"/notify - notification settings\n" +
+		"/settings - bot settings\n" +
+		"/text - override bot texts\n" +
+		"/language - interface language",
+	"moderator_panel": "🛠️ Moderator panel\n\nThis is a bot for anonymous suggestions. Users send suggestions in private messages and you moderate them.\n\n" +
+		"Available commands:\n" +
+		"/proposals - review suggestions\n" +
+		"/post <number> - manage a published post\n" +
+		"/search <text> - search suggestions\n" +
+		"/stats [csv] - suggestion statistics\n" +
+		"/notify - notification settings\n" +
+		"/language - interface language",
+
+	"no_access":          "❌ You don't have access to this feature.",
+	"callback_no_access": "❌ Access denied.",
+
+	"admin_owner_only_add":     "❌ Only the bot owner can add administrators.",
+	"admin_add_usage":          "📝 Usage: /addadmin <user_ID>\n\nExample: /addadmin 123456789",
+	"admin_add_bad_id":         "❌ Invalid ID format. Use: /addadmin <user_ID>\n\nExample: /addadmin 123456789",
+	"admin_add_is_owner":       "❌ You are already the bot owner.",
+	"admin_add_exists":         "❌ User with ID %d is already an administrator.",
+	"admin_add_error":          "❌ Failed to add administrator: %s",
+	"admin_added":              "✅ User %s (ID: %d) has been added as an administrator!",
+	"admin_added_notification": "🎉 You have been added as a moderator of the suggestion bot!\n\nUse /start to open the moderation panel.",
+	"admin_notify_failed":      "⚠️ Administrator added, but the notification could not be delivered.",
+	"admin_notify_sent":        "✅ The new administrator has been notified.",
+	"admin_owner_only_list":    "❌ Only the bot owner can view the list of administrators.",
+	"admin_list_error":         "❌ Failed to get the list of administrators: %s",
+	"admin_list_empty":         "📋 The moderator list is empty.",
+	"admin_list_header":        "📋 Moderators:\n\n",
+	"admin_list_owner":         "👑 Owner: ID %d\n",
+
+	"media_photo":         "🖼️ Photo",
+	"media_document":      "📄 Document: %s",
+	"media_video":         "🎥 Video",
+	"media_video_note":    "📹 Video note",
+	"media_audio":         "🎵 %s",
+	"media_audio_default": "Audio",
+	"media_voice":         "🎤 Voice message",
+	"media_sticker":       "😊 Sticker",
+	"media_other":         "📦 Media content",
+
+	"media_preview_failed": "❌ Could not display the media file (type: %s)\n💬 Description: %s",
+	"publish_text_prefix":  "💡 New suggestion:\n\n%s",
+
+	"proposals_error":    "❌ Failed to get suggestions: %s",
+	"proposals_empty":    "✅ No new suggestions to moderate.",
+	"proposals_found":    "📨 Found %d suggestions to moderate:",
+	"moderation_card":    "📨 Anonymous suggestion #%d\n\n⏰ Time: %s\n\nChoose an action:",
+	"btn_approve":        "✅ APPROVE",
+	"btn_reject":         "❌ REJECT",
+	"proposal_not_found": "❌ Error: suggestion not found",
+	"publish_error":      "❌ Failed to publish",
+	"proposal_published": "✅ Suggestion published!",
+	"proposal_rejected":  "✅ Suggestion rejected!",
+
+	"proposal_too_long":   "❌ The suggestion is too long. Maximum is %d characters.",
+	"proposal_save_error": "❌ Something went wrong while sending your suggestion. Please try again later.",
+	"proposal_accepted":   "✅ Your suggestion has been received! The moderators will review it anonymously.\n\n🎫 Tracking code: %s\nCheck the status: /status %s",
+	"admin_new_proposal": "📨 A new anonymous suggestion has arrived!\n\n" +
+		"💬 Text: %s\n" +
+		"📁 Type: %s\n\n" +
+		"Use /proposals to review all suggestions.",
+
+	"draft_preview_hint": "👆 This is how your suggestion will look once published.\n\nSend it to the moderators?",
+	"btn_draft_send":     "📨 Send",
+	"btn_draft_edit":     "✏️ Edit",
+	"btn_draft_cancel":   "🗑 Cancel",
+	"draft_edit_hint":    "✏️ Send the corrected version - it will replace the current draft.",
+	"draft_sent":         "📨 Draft sent.",
+	"draft_cancelled":    "🗑 Draft deleted.",
+	"draft_replaced":     "🔄 Draft replaced with a new one.",
+	"draft_expired":      "⌛ The draft was not sent and has expired.",
+	"draft_outdated":     "❌ This draft is no longer valid",
+
+	"cancel_nothing": "ℹ️ Nothing to cancel.",
+	"cancel_done":    "✅ Input cancelled.",
+
+	"settings_owner_only":     "❌ Only the bot owner can change settings.",
+	"settings_title":          "⚙️ Bot settings\n\n",
+	"settings_default_marker": " (default)",
+	"settings_on":             "✅ on",
+	"settings_off":            "❌ off",
+	"settings_toggled":        "✅ Setting changed",
+	"settings_save_error":     "❌ Failed to save",
+	"settings_edit_prompt":    "✏️ %s\n\nCurrent value:\n%s\n\nSend a new value or /cancel to abort.",
+	"btn_reset_default":       "↩️ Default",
+	"settings_reset_error":    "❌ Failed to reset",
+	"settings_reset_done":     "✅ Value reset",
+	"settings_invalid":        "❌ %s\n\nTry again or send /cancel.",
+	"settings_save_failed":    "❌ Failed to save the setting: %s",
+	"settings_saved":          "✅ Setting \"%s\" saved.",
+
+	"validation_empty":    "the value can't be empty",
+	"validation_int":      "an integer is expected",
+	"validation_negative": "the value can't be negative",
+	"validation_bool":     "true or false is expected",
+	"validation_lang":     "unknown language, available: %s",
+	"validation_choice":   "allowed values: %s",
+
+	"setting_channel_id":            "Channel ID",
+	"setting_notify_admins":         "Notify moderators",
+	"setting_max_text_length":       "Max suggestion length (0 - unlimited)",
+	"setting_default_lang":          "Default language",
+	"setting_channel_lang":          "Channel post language",
+	"setting_submission_mode":       "Submission mode",
+	"setting_draft_timeout_minutes": "Draft lifetime, min (0 - unlimited)",
+	"submission_mode_instant":       "instant",
+	"submission_mode_confirm":       "with confirmation",
+
+	"language_choose": "🌐 Choose a language:",
+	"language_set":    "✅ Interface language: English.",
+	"language_error":  "❌ Failed to save the language",
+
+	"text_owner_only": "❌ Only the bot owner can change texts.",
+	"text_usage": "📝 Usage:\n" +
+		"/text keys - list of keys\n" +
+		"/text <lang> <key> - current text\n" +
+		"/text <lang> <key> <text> - set a custom text\n" +
+		"/text <lang> <key> - - restore the original text\n\n" +
+		"Languages: %s",
+	"text_unknown_lang": "❌ Unknown language. Available: %s",
+	"text_unknown_key":  "❌ Unknown key %s. List of keys: /text keys",
+	"text_current":      "🔤 %s [%s]%s:\n\n%s",
+	"text_overridden":   " (customized)",
+	"text_saved":        "✅ Text %s [%s] saved.",
+	"text_reset":        "✅ Text %s [%s] restored to the original.",
+	"text_error":        "❌ Failed to save the text: %s",
+	"text_keys":         "🔤 Text keys:\n\n%s",
+	"text_bad_verbs":    "❌ The text must contain the same placeholders in the same order as the original: %s",
+
+	"submission_mode_draft": "from several messages",
+	"btn_draft_preview":     "👁 Preview",
+	"draft_collecting":      "📝 Messages in the draft: %d.\n\nSend more messages to add them to the same suggestion, or press \"Send\".",
+	"draft_auto_sent":       "⌛ The draft timed out and has been sent to the moderators.",
+	"draft_too_many_parts":  "❌ The draft already has %d messages. Send it or start a new one.",
+
+	"proposal_status_error":    "❌ Failed to update the status",
+	"proposal_already_decided": "ℹ️ The suggestion has already been reviewed",
+	"proposal_withdrawn":       "✅ Suggestion #%d withdrawn",
+
+	"my_header":        "📋 Your suggestions:\n\n",
+	"my_empty":         "📭 You haven't sent any suggestions yet.",
+	"my_error":         "❌ Failed to get your suggestions. Please try again later.",
+	"my_post_link":     "🔗 %s\n",
+	"btn_withdraw":     "↩️ Withdraw #%d",
+	"status_pending":   "⏳ under review",
+	"status_approved":  "✅ published",
+	"status_rejected":  "❌ rejected",
+	"status_withdrawn": "↩️ withdrawn",
+
+	"ticket_usage":     "📝 Usage: /status <code>\n\nYou get the tracking code right after sending a suggestion.",
+	"ticket_not_found": "❌ No suggestion with this code was found.",
+	"ticket_status":    "🎫 %s\n\nStatus: %s\nSent: %s",
+
+	"moderation_card_edited": "✏️ Edited by the author: %s",
+	"proposal_changed":       "✏️ The author has changed the suggestion. Please review the new version.",
+	"proposal_edit_saved":    "✏️ Suggestion #%d updated.",
+	"proposal_edit_locked":   "ℹ️ Suggestion #%d has already been reviewed and can no longer be changed.",
+	"proposal_edit_error":    "❌ Failed to save the changes. Please try again later.",
+
+	"setting_forward_policy":    "Forwarded messages",
+	"forward_policy_reject":     "reject",
+	"forward_policy_show":       "show the source to moderators",
+	"forward_policy_attribute":  "publish with the source",
+	"forward_rejected":          "❌ Forwarded messages are not accepted. Please send your own message.",
+	"moderation_card_forwarded": "↪️ Forwarded from: %s",
+	"publish_via":               "via %s",
+
+	"setting_staging_chat_id": "Staging chat for exact copies (0 - off)",
+
+	"setting_post_header":    "Post header",
+	"setting_post_footer":    "Post footer",
+	"setting_post_signature": "Post signature",
+	"settings_empty_value":   "—",
+	"settings_template_hint": "Available placeholders: %s\nSend \"-\" to clear.",
+	"validation_placeholder": "unknown placeholder %s, available: %s",
+
+	"setting_suggest_button":   "\"Suggest a post\" button under posts",
+	"btn_suggest_post":         "✍️ Suggest a post",
+	"post_buttons_text":        "🔗",
+	"btn_post_buttons":         "🔗 Buttons (%d)",
+	"buttons_prompt":           "🔗 Buttons for post #%d\n\nCurrent:\n%s\n\nSend buttons as \"Text | https://link\", one per line. \"-\" removes all buttons, /cancel aborts.",
+	"buttons_invalid":          "❌ %s\n\nTry again or send /cancel.",
+	"buttons_error":            "❌ Failed to save the buttons",
+	"buttons_saved":            "✅ Buttons on the post: %d",
+	"validation_button_format": "line \"%s\" is not in the \"Text | link\" format",
+	"validation_button_url":    "invalid link %s",
+	"validation_button_count":  "no more than %d buttons",
+
+	"status_deleted":          "🗑 removed from the channel",
+	"post_usage":              "📝 Usage: /post <suggestion number>",
+	"post_info":               "📢 Suggestion #%d\n\nStatus: %s\nMessages in the channel: %d\n\n%s",
+	"post_history":            "📜 Log:",
+	"action_approve":          "approved",
+	"action_reject":           "rejected",
+	"action_edit":             "text edited",
+	"action_delete":           "removed from the channel",
+	"btn_post_edit":           "✏️ Edit text",
+	"btn_post_delete":         "🗑 Remove from the channel",
+	"btn_post_delete_confirm": "⚠️ Yes, remove",
+	"post_edit_prompt":        "✏️ Send the new text for post #%d or /cancel to abort.",
+	"post_edit_empty":         "❌ The text can't be empty. Try again or send /cancel.",
+	"post_not_published":      "❌ The post is not published",
+	"post_edit_error":         "❌ Failed to edit the post",
+	"post_edit_no_text":       "❌ This post has no text that can be edited",
+	"post_edit_too_long":      "❌ The new text doesn't fit into the post",
+	"post_edited":             "✅ Post #%d edited",
+	"post_delete_error":       "❌ Failed to remove the post. Telegram doesn't allow deleting messages older than 48 hours.",
+	"post_deleted":            "🗑 The post has been removed from the channel",
+
+	"setting_undo_seconds":    "Time to undo an approval, s (0 - no undo)",
+	"status_scheduled":        "⏳ about to be published",
+	"proposal_scheduled":      "⏳ Publishing in %ds",
+	"proposal_scheduled_card": "⏳ Suggestion #%d will be published in %ds.",
+	"btn_undo":                "↩️ Undo",
+	"proposal_undone":         "↩️ Publishing cancelled",
+	"proposal_undone_card":    "↩️ Publishing of suggestion #%d was cancelled, it is back in the queue: /proposals",
+	"proposal_undo_too_late":  "ℹ️ Suggestion #%d has already been published. Remove the post from the channel?",
+	"action_undo":             "approval undone",
+
+	"notify_info":              "🔔 New suggestion notifications\n\nMode: %s\nQuiet hours: %s\n\nCommands:\n/notify now - immediately\n/notify digest <minutes> - as a digest\n/notify mute - no notifications\n/notify quiet <from>-<to> - quiet hours, e.g. 23-8\n/notify quiet off - no quiet hours",
+	"notify_mode_immediate":    "immediately",
+	"notify_mode_digest_every": "digest every %d min",
+	"notify_mode_muted":        "muted",
+	"notify_quiet_off":         "none",
+	"notify_globally_off":      "⚠️ Admin notifications are turned off by the owner in /settings.",
+	"notify_usage":             "📝 Usage: /notify [now | digest <minutes> | mute | quiet <from>-<to> | quiet off]",
+	"notify_saved":             "✅ Notification settings saved",
+	"notify_error":             "❌ Failed to save notification settings.",
+	"notify_unknown_mode":      "❌ Unknown notification mode.",
+	"btn_notify_immediate":     "🔔 Immediately",
+	"btn_notify_digest":        "📬 Digest",
+	"btn_notify_muted":         "🔕 Off",
+	"notify_digest_message":    "📬 New suggestions: %d, the oldest has been waiting %s.\n\nUse /proposals to review them.",
+	"age_minutes":              "%d min",
+	"age_hours":                "%dh %dm",
+	"age_days":                 "%dd %dh",
+
+	"setting_stale_minutes":    "Remind about a stale queue after, min (0 - off)",
+	"setting_escalate_minutes": "Alert the owner about a stale queue after, min (0 - off)",
+	"proposals_oldest":         "⏱ The oldest has been waiting %s.",
+	"queue_reminder":           "⏰ The suggestion queue is stale: the oldest has been waiting %s.\n\nUse /proposals to review them.",
+	"queue_escalation":         "🚨 Suggestion #%d has been waiting for moderation for %s. Moderators are falling behind - check the queue: /proposals",
+
+	"setting_pending_ttl_days": "Remove suggestions from the queue after, days (0 - never)",
+	"setting_expire_notify":    "Tell the author when a suggestion expires",
+	"status_expired":           "⌛ expired without review",
+	"action_expire":            "expired",
+	"proposal_expired":         "⌛ Your suggestion %s was not reviewed within %d days and has been removed from the queue. You can send it again.",
+
+	"setting_report_days": "Moderator report to the owner every, days (0 - off)",
+	"stats_error":         "❌ Failed to calculate statistics.",
+	"stats_report_title":  "📊 Report for the last %d days",
+	"modstats_title":      "👥 Moderator statistics",
+	"modstats_empty":      "👥 Moderators have not reviewed any suggestions yet.",
+	"modstats_unknown":    "former moderator",
+	"modstats_line":       "✅ %d · ❌ %d · ⏱ median %s\n📅 day %d · week %d · month %d",
+
+	"stats_empty":    "📊 There have been no suggestions yet.",
+	"stats_title":    "📊 Suggestion statistics\n\nTotal: %d",
+	"stats_days":     "📅 By day:",
+	"stats_weeks":    "🗓 By week:",
+	"stats_statuses": "By status:",
+	"stats_media":    "By type:",
+	"stats_wait":     "⏱ Average wait for a decision: %s",
+	"stats_hours":    "🕒 Busiest hours:",
+
+	"export_error":   "❌ Failed to export the database.",
+	"export_caption": "💾 Backup: %d suggestions, %d administrators, %d settings.\n\nRestore with: ./bot import <file.json>",
+
+	"search_usage":    "📝 Usage: /search [status:<status>] [type:<type>] [from:YYYY-MM-DD] [to:YYYY-MM-DD] <text>\n\nExample: /search status:approved type:photo kittens",
+	"search_error":    "❌ Failed to search suggestions.",
+	"search_empty":    "🔎 Nothing found.",
+	"search_results":  "🔎 Found: %d (page %d of %d)",
+	"search_outdated": "❌ These results are outdated, run /search again",
+	"btn_search_prev": "◀️ Back",
+	"btn_search_next": "Next ▶️",
+
+	"setting_retention_rejected_days":  "Wipe rejected suggestions after, days (0 - keep)",
+	"setting_retention_published_days": "Anonymise published suggestions after, days (0 - keep)",
+	"setting_retention_sender_days":    "Delete sender references after, days (0 - keep)",
+	"purge_error":                      "❌ Failed to check the data cleanup.",
+	"purge_forever":                    "kept forever",
+	"purge_days":                       "%d days",
+	"purge_report": "🧹 Retention cleanup (dry run, nothing was changed)\n\n" +
+		"❌ Rejected, withdrawn and expired (%s): content of %d will be wiped\n" +
+		"✅ Published (%s): %d will be anonymised\n" +
+		"👤 Sender references (%s): %d will be deleted\n\n" +
+		"The cleanup runs automatically every hour. Periods are set in /settings.",
+}
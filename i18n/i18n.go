@@ -0,0 +1,231 @@
+package i18n
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"telegram-bot/database"
+	"telegram-bot/logger"
+	"telegram-bot/settings"
+
+	"github.com/mymmrac/telego"
+)
+
+const fallbackLang = "ru"
+
+var catalogs = map[string]map[string]string{
+	"ru": ru,
+	"en": en,
+}
+
+var languageNames = map[string]string{
+	"ru": "🇷🇺 Русский",
+	"en": "🇬🇧 English",
+}
+
+// Languages возвращает коды поддерживаемых языков
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+func LanguageName(lang string) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// Keys возвращает все ключи строк каталога
+func Keys() []string {
+	keys := make([]string, 0, len(catalogs[fallbackLang]))
+	for key := range catalogs[fallbackLang] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func HasKey(key string) bool {
+	_, ok := catalogs[fallbackLang][key]
+	return ok
+}
+
+// OverrideKey возвращает ключ настройки, в которой хранится переопределённая владельцем строка
+func OverrideKey(lang, key string) string {
+	return "text." + lang + "." + key
+}
+
+// Localizer выбирает язык пользователя и переводит строки с учётом переопределений владельца
+type Localizer struct {
+	db       database.Storage
+	settings *settings.Store
+	mu       sync.RWMutex
+	langs    map[int64]string
+}
+
+func NewLocalizer(db database.Storage, store *settings.Store) *Localizer {
+	return &Localizer{
+		db:       db,
+		settings: store,
+		langs:    make(map[int64]string),
+	}
+}
+
+// Printer переводит строки на выбранный язык
+type Printer struct {
+	localizer *Localizer
+	Lang      string
+}
+
+func (p Printer) T(key string, args ...any) string {
+	return p.localizer.Translate(p.Lang, key, args...)
+}
+
+// For выбирает язык по сохранённому выбору пользователя или по From.LanguageCode
+func (l *Localizer) For(user *telego.User) Printer {
+	if user == nil {
+		return l.Default()
+	}
+
+	if lang := l.storedLang(user.ID); lang != "" {
+		return Printer{localizer: l, Lang: lang}
+	}
+
+	code := strings.ToLower(user.LanguageCode)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if IsSupported(code) {
+		return Printer{localizer: l, Lang: code}
+	}
+
+	return l.Default()
+}
+
+// ForUserID используется, когда сообщение отправляется пользователю без входящего апдейта
+func (l *Localizer) ForUserID(userID int64) Printer {
+	if lang := l.storedLang(userID); lang != "" {
+		return Printer{localizer: l, Lang: lang}
+	}
+	return l.Default()
+}
+
+// Channel возвращает язык публикаций в канале
+func (l *Localizer) Channel() Printer {
+	return l.printer(l.settings.Get(settings.KeyChannelLang))
+}
+
+// Lang возвращает переводчик для заранее известного языка
+func (l *Localizer) Lang(lang string) Printer {
+	return l.printer(lang)
+}
+
+func (l *Localizer) Default() Printer {
+	return l.printer(l.settings.Get(settings.KeyDefaultLang))
+}
+
+func (l *Localizer) printer(lang string) Printer {
+	if !IsSupported(lang) {
+		lang = fallbackLang
+	}
+	return Printer{localizer: l, Lang: lang}
+}
+
+// SetUserLang сохраняет выбор языка пользователем
+func (l *Localizer) SetUserLang(userID int64, lang string) error {
+	if !IsSupported(lang) {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	if err := l.db.SetUserLanguage(userID, lang); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.langs[userID] = lang
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Localizer) storedLang(userID int64) string {
+	l.mu.RLock()
+	lang, ok := l.langs[userID]
+	l.mu.RUnlock()
+	if ok {
+		return lang
+	}
+
+	lang, err := l.db.GetUserLanguage(userID)
+	if err != nil {
+		slog.Error("Ошибка получения языка пользователя", logger.User(userID), "error", err)
+		return ""
+	}
+
+	l.mu.Lock()
+	l.langs[userID] = lang
+	l.mu.Unlock()
+	return lang
+}
+
+// Translate возвращает строку на языке lang: переопределение владельца, каталог языка, затем резервный каталог
+func (l *Localizer) Translate(lang, key string, args ...any) string {
+	text, ok := l.lookup(lang, key)
+	if !ok {
+		text, ok = l.lookup(fallbackLang, key)
+	}
+	if !ok {
+		slog.Warn("Отсутствует перевод строки", "key", key)
+		text = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Source возвращает строку без переопределений и подстановки аргументов
+func Source(lang, key string) string {
+	if text, ok := catalogs[lang][key]; ok {
+		return text
+	}
+	return catalogs[fallbackLang][key]
+}
+
+// verbPattern находит глаголы форматирования fmt, кроме экранированного %%
+var verbPattern = regexp.MustCompile(`%[-+# 0]*(?:\[\d+\])?(?:\d+|\*)?(?:\.(?:\d+|\*))?(?:\[\d+\])?[a-zA-Z%]`)
+
+// Verbs возвращает глаголы форматирования строки по порядку. Переопределение строки
+// должно содержать те же глаголы, иначе Sprintf выведет %!(EXTRA ...) или %!s(MISSING).
+func Verbs(text string) []string {
+	var verbs []string
+	for _, verb := range verbPattern.FindAllString(text, -1) {
+		if verb != "%%" {
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
+
+func (l *Localizer) lookup(lang, key string) (string, bool) {
+	overrideKey := OverrideKey(lang, key)
+	if !l.settings.IsDefault(overrideKey) {
+		return l.settings.Get(overrideKey), true
+	}
+
+	text, ok := catalogs[lang][key]
+	return text, ok
+}
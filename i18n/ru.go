@@ -0,0 +1,326 @@
+package i18n
+
+var ru = map[string]string{
+	"welcome": `🤖 Добро пожаловать в анонимную предложку!
+
+Просто отправьте сюда ваше предложение, идею или сообщение, и оно будет анонимно рассмотрено модераторами.
+
+Ваша личность будет скрыта - модераторы увидят только содержание вашего сообщения.
+
+❓ Что можно отправлять:
+• Текстовые предложения
+• Фотографии
+• Документы
+• Видео
+• Кружочки (видеосообщения)
+• Аудио и голосовые сообщения
+• Стикеры
+• Идеи и пожелания
+
+Ваше предложение будет рассмотрено в ближайшее время!
+
+📋 Ваши предложения: /my
+🎫 Статус по коду: /status <код>
+🌐 Сменить язык: /language`,
+	"owner_panel": "👑 Панель владельца\n\nЭто бот для анонимных предложений. Пользователи присылают предложения в ЛС, а вы их модерируете.\n\n" +
+		"Доступные команды:\n" +
+		"/addadmin <ID> - добавить администратора\n" +
+		"/admins - список администраторов\n" +
+		"/modstats - статистика модераторов\n" +
+		"/export - резервная копия базы данных\n" +
+		"/purge - что удалит очистка по срокам хранения\n" +
+		"/proposals - просмотр предложений\n" +
+		"/post <номер> - управление опубликованным постом\n" +
+		"/search <текст> - поиск по предложениям\n" +
+		"/stats [csv] - статистика предложений\n" +
+		"/notify - настройка уведомлений\n" +
+		"/settings - настройки бота\n" +
+		"/text - переопределение текстов бота\n" +
+		"/language - язык интерфейса",
+	"moderator_panel": "🛠️ Панель модератора\n\nЭто бот для анонимных предложений. Пользователи присылают предложения в ЛС, а вы их модерируете.\n\n" +
+		"Доступные команды:\n" +
+		"/proposals - просмотр предложений\n" +
+		"/post <номер> - управление опубликованным постом\n" +
+		"/search <текст> - поиск по предложениям\n" +
+		"/stats [csv] - статистика предложений\n" +
+		"/notify - настройка уведомлений\n" +
+		"/language - язык интерфейса",
+
+	"no_access":          "❌ У вас нет доступа к этой функции.",
+	"callback_no_access": "❌ У вас нет доступа.",
+
+	"admin_owner_only_add":     "❌ Только владелец бота может добавлять администраторов.",
+	"admin_add_usage":          "📝 Использование: /addadmin <ID_пользователя>\n\nПример: /addadmin 123456789",
+	"admin_add_bad_id":         "❌ Неверный формат ID. Используйте: /addadmin <ID_пользователя>\n\nПример: /addadmin 123456789",
+	"admin_add_is_owner":       "❌ Вы уже являетесь владельцем бота.",
+	"admin_add_exists":         "❌ Пользователь с ID %d уже является администратором.",
+	"admin_add_error":          "❌ Ошибка при добавлении администратора: %s",
+	"admin_added":              "✅ Пользователь %s (ID: %d) добавлен как администратор!",
+	"admin_added_notification": "🎉 Вы были добавлены как модератор бота-предложки!\n\nИспользуйте команду /start для доступа к панели модерации.",
+	"admin_notify_failed":      "⚠️ Администратор добавлен, но не удалось отправить ему уведомление.",
+	"admin_notify_sent":        "✅ Уведомление отправлено новому администратору.",
+	"admin_owner_only_list":    "❌ Только владелец бота может просматривать список администраторов.",
+	"admin_list_error":         "❌ Ошибка при получении списка администраторов: %s",
+	"admin_list_empty":         "📋 Список модераторов пуст.",
+	"admin_list_header":        "📋 Список модераторов:\n\n",
+	"admin_list_owner":         "👑 Владелец: ID %d\n",
+
+	"media_photo":         "🖼️ Фото",
+	"media_document":      "📄 Документ: %s",
+	"media_video":         "🎥 Видео",
+	"media_video_note":    "📹 Кружочек (видеосообщение)",
+	"media_audio":         "🎵 %s",
+	"media_audio_default": "Аудио",
+	"media_voice":         "🎤 Голосовое сообщение",
+	"media_sticker":       "😊 Стикер",
+	"media_other":         "📦 Медиа-контент",
+
+	"media_preview_failed": "❌ Не удалось отобразить медиафайл (тип: %s)\n💬 Описание: %s",
+	"publish_text_prefix":  "💡 Новое предложение:\n\n%s",
+
+	"proposals_error":    "❌ Ошибка при получении предложений: %s",
+	"proposals_empty":    "✅ Нет новых предложений для модерации.",
+	"proposals_found":    "📨 Найдено %d предложений для модерации:",
+	"moderation_card":    "📨 Анонимное предложение #%d\n\n⏰ Время: %s\n\nВыберите действие:",
+	"btn_approve":        "✅ ОДОБРИТЬ",
+	"btn_reject":         "❌ ОТКЛОНИТЬ",
+	"proposal_not_found": "❌ Ошибка: предложение не найдено",
+	"publish_error":      "❌ Ошибка при публикации",
+	"proposal_published": "✅ Предложение опубликовано!",
+	"proposal_rejected":  "✅ Предложение отклонено!",
+
+	"proposal_too_long":   "❌ Предложение слишком длинное. Максимум %d символов.",
+	"proposal_save_error": "❌ Произошла ошибка при отправке предложения. Попробуйте позже.",
+	"proposal_accepted":   "✅ Ваше предложение принято! Оно будет рассмотрено модераторами анонимно.\n\n🎫 Код для отслеживания: %s\nПроверить статус: /status %s",
+	"admin_new_proposal": "📨 Поступило новое анонимное предложение!\n\n" +
+		"💬 Текст: %s\n" +
+		"📁 Тип: %s\n\n" +
+		"Используйте /proposals для просмотра всех предложений.",
+
+	"draft_preview_hint": "👆 Так будет выглядеть ваше предложение после публикации.\n\nОтправить его модераторам?",
+	"btn_draft_send":     "📨 Отправить",
+	"btn_draft_edit":     "✏️ Изменить",
+	"btn_draft_cancel":   "🗑 Отменить",
+	"draft_edit_hint":    "✏️ Отправьте исправленный вариант - он заменит текущий черновик.",
+	"draft_sent":         "📨 Черновик отправлен.",
+	"draft_cancelled":    "🗑 Черновик удалён.",
+	"draft_replaced":     "🔄 Черновик заменён новым.",
+	"draft_expired":      "⌛ Черновик не был отправлен и удалён по истечении времени.",
+	"draft_outdated":     "❌ Этот черновик уже неактуален",
+
+	"cancel_nothing": "ℹ️ Нечего отменять.",
+	"cancel_done":    "✅ Ввод отменён.",
+
+	"settings_owner_only":     "❌ Только владелец бота может изменять настройки.",
+	"settings_title":          "⚙️ Настройки бота\n\n",
+	"settings_default_marker": " (по умолчанию)",
+	"settings_on":             "✅ вкл",
+	"settings_off":            "❌ выкл",
+	"settings_toggled":        "✅ Настройка изменена",
+	"settings_save_error":     "❌ Ошибка при сохранении",
+	"settings_edit_prompt":    "✏️ %s\n\nТекущее значение:\n%s\n\nОтправьте новое значение или /cancel для отмены.",
+	"btn_reset_default":       "↩️ По умолчанию",
+	"settings_reset_error":    "❌ Ошибка при сбросе",
+	"settings_reset_done":     "✅ Значение сброшено",
+	"settings_invalid":        "❌ %s\n\nПопробуйте ещё раз или отправьте /cancel.",
+	"settings_save_failed":    "❌ Ошибка при сохранении настройки: %s",
+	"settings_saved":          "✅ Настройка «%s» сохранена.",
+
+	"validation_empty":    "значение не может быть пустым",
+	"validation_int":      "ожидается целое число",
+	"validation_negative": "значение не может быть отрицательным",
+	"validation_bool":     "ожидается true или false",
+	"validation_lang":     "неизвестный язык, доступны: %s",
+	"validation_choice":   "допустимые значения: %s",
+
+	"setting_channel_id":            "ID канала",
+	"setting_notify_admins":         "Уведомлять модераторов",
+	"setting_max_text_length":       "Макс. длина предложения (0 - без ограничений)",
+	"setting_default_lang":          "Язык по умолчанию",
+	"setting_channel_lang":          "Язык публикаций в канале",
+	"setting_submission_mode":       "Режим отправки",
+	"setting_draft_timeout_minutes": "Время жизни черновика, мин. (0 - без ограничений)",
+	"submission_mode_instant":       "сразу",
+	"submission_mode_confirm":       "с подтверждением",
+
+	"language_choose": "🌐 Выберите язык:",
+	"language_set":    "✅ Язык интерфейса: русский.",
+	"language_error":  "❌ Не удалось сохранить язык",
+
+	"text_owner_only": "❌ Только владелец бота может изменять тексты.",
+	"text_usage": "📝 Использование:\n" +
+		"/text keys - список ключей\n" +
+		"/text <язык> <ключ> - текущий текст\n" +
+		"/text <язык> <ключ> <текст> - задать свой текст\n" +
+		"/text <язык> <ключ> - - вернуть исходный текст\n\n" +
+		"Языки: %s",
+	"text_unknown_lang": "❌ Неизвестный язык. Доступны: %s",
+	"text_unknown_key":  "❌ Неизвестный ключ %s. Список ключей: /text keys",
+	"text_current":      "🔤 %s [%s]%s:\n\n%s",
+	"text_overridden":   " (изменён)",
+	"text_saved":        "✅ Текст %s [%s] сохранён.",
+	"text_reset":        "✅ Текст %s [%s] возвращён к исходному.",
+	"text_error":        "❌ Ошибка при сохранении текста: %s",
+	"text_keys":         "🔤 Ключи текстов:\n\n%s",
+	"text_bad_verbs":    "❌ Текст должен содержать те же подстановки в том же порядке, что и исходный: %s",
+
+	"submission_mode_draft": "из нескольких сообщений",
+	"btn_draft_preview":     "👁 Предпросмотр",
+	"draft_collecting":      "📝 В черновике сообщений: %d.\n\nОтправьте ещё сообщения, чтобы добавить их в это же предложение, или нажмите «Отправить».",
+	"draft_auto_sent":       "⌛ Время черновика истекло, он отправлен модераторам.",
+	"draft_too_many_parts":  "❌ В черновике уже %d сообщений. Отправьте его или начните новый.",
+
+	"proposal_status_error":    "❌ Ошибка при обновлении статуса",
+	"proposal_already_decided": "ℹ️ Предложение уже рассмотрено",
+	"proposal_withdrawn":       "✅ Предложение #%d отозвано",
+
+	"my_header":        "📋 Ваши предложения:\n\n",
+	"my_empty":         "📭 Вы ещё не отправляли предложений.",
+	"my_error":         "❌ Не удалось получить список предложений. Попробуйте позже.",
+	"my_post_link":     "🔗 %s\n",
+	"btn_withdraw":     "↩️ Отозвать #%d",
+	"status_pending":   "⏳ на рассмотрении",
+	"status_approved":  "✅ опубликовано",
+	"status_rejected":  "❌ отклонено",
+	"status_withdrawn": "↩️ отозвано",
+
+	"ticket_usage":     "📝 Использование: /status <код>\n\nКод отслеживания приходит сразу после отправки предложения.",
+	"ticket_not_found": "❌ Предложение с таким кодом не найдено.",
+	"ticket_status":    "🎫 %s\n\nСтатус: %s\nОтправлено: %s",
+
+	"moderation_card_edited": "✏️ Изменено автором: %s",
+	"proposal_changed":       "✏️ Автор изменил предложение. Проверьте новую версию.",
+	"proposal_edit_saved":    "✏️ Предложение #%d обновлено.",
+	"proposal_edit_locked":   "ℹ️ Предложение #%d уже рассмотрено, изменить его нельзя.",
+	"proposal_edit_error":    "❌ Не удалось сохранить изменения. Попробуйте позже.",
+
+	"setting_forward_policy":    "Пересланные сообщения",
+	"forward_policy_reject":     "отклонять",
+	"forward_policy_show":       "показывать источник модераторам",
+	"forward_policy_attribute":  "публиковать с источником",
+	"forward_rejected":          "❌ Пересланные сообщения не принимаются. Отправьте своё сообщение.",
+	"moderation_card_forwarded": "↪️ Переслано из: %s",
+	"publish_via":               "via %s",
+
+	"setting_staging_chat_id": "Служебный чат для точных копий (0 - выкл.)",
+
+	"setting_post_header":    "Шапка поста",
+	"setting_post_footer":    "Подвал поста",
+	"setting_post_signature": "Подпись поста",
+	"settings_empty_value":   "—",
+	"settings_template_hint": "Доступные подстановки: %s\nОтправьте «-», чтобы очистить.",
+	"validation_placeholder": "неизвестная подстановка %s, доступны: %s",
+
+	"setting_suggest_button":   "Кнопка «Предложить пост» под постами",
+	"btn_suggest_post":         "✍️ Предложить пост",
+	"post_buttons_text":        "🔗",
+	"btn_post_buttons":         "🔗 Кнопки (%d)",
+	"buttons_prompt":           "🔗 Кнопки для поста #%d\n\nТекущие:\n%s\n\nОтправьте кнопки в формате «Текст | https://ссылка», по одной на строку. «-» - убрать все кнопки, /cancel - отмена.",
+	"buttons_invalid":          "❌ %s\n\nПопробуйте ещё раз или отправьте /cancel.",
+	"buttons_error":            "❌ Не удалось сохранить кнопки",
+	"buttons_saved":            "✅ Кнопок у поста: %d",
+	"validation_button_format": "строка «%s» не в формате «Текст | ссылка»",
+	"validation_button_url":    "неверная ссылка %s",
+	"validation_button_count":  "не больше %d кнопок",
+
+	"status_deleted":          "🗑 удалено из канала",
+	"post_usage":              "📝 Использование: /post <номер предложения>",
+	"post_info":               "📢 Предложение #%d\n\nСтатус: %s\nСообщений в канале: %d\n\n%s",
+	"post_history":            "📜 Журнал:",
+	"action_approve":          "одобрено",
+	"action_reject":           "отклонено",
+	"action_edit":             "текст изменён",
+	"action_delete":           "удалено из канала",
+	"btn_post_edit":           "✏️ Изменить текст",
+	"btn_post_delete":         "🗑 Удалить из канала",
+	"btn_post_delete_confirm": "⚠️ Да, удалить",
+	"post_edit_prompt":        "✏️ Отправьте новый текст поста #%d или /cancel для отмены.",
+	"post_edit_empty":         "❌ Текст не может быть пустым. Попробуйте ещё раз или отправьте /cancel.",
+	"post_not_published":      "❌ Пост не опубликован",
+	"post_edit_error":         "❌ Не удалось изменить пост",
+	"post_edit_no_text":       "❌ В этом посте нет текста, который можно изменить",
+	"post_edit_too_long":      "❌ Новый текст не помещается в пост",
+	"post_edited":             "✅ Пост #%d изменён",
+	"post_delete_error":       "❌ Не удалось удалить пост. Telegram не позволяет удалять сообщения старше 48 часов.",
+	"post_deleted":            "🗑 Пост удалён из канала",
+
+	"setting_undo_seconds":    "Время на отмену одобрения, с (0 - без отмены)",
+	"status_scheduled":        "⏳ скоро будет опубликовано",
+	"proposal_scheduled":      "⏳ Публикация через %d с",
+	"proposal_scheduled_card": "⏳ Предложение #%d будет опубликовано через %d с.",
+	"btn_undo":                "↩️ Отменить",
+	"proposal_undone":         "↩️ Публикация отменена",
+	"proposal_undone_card":    "↩️ Публикация предложения #%d отменена, оно возвращено в очередь: /proposals",
+	"proposal_undo_too_late":  "ℹ️ Предложение #%d уже опубликовано. Удалить пост из канала?",
+	"action_undo":             "одобрение отменено",
+
+	"notify_info":              "🔔 Уведомления о новых предложениях\n\nРежим: %s\nЧасы тишины: %s\n\nКоманды:\n/notify now - сразу\n/notify digest <минуты> - сводкой\n/notify mute - без уведомлений\n/notify quiet <с>-<до> - часы тишины, например 23-8\n/notify quiet off - без часов тишины",
+	"notify_mode_immediate":    "сразу",
+	"notify_mode_digest_every": "сводка раз в %d мин",
+	"notify_mode_muted":        "без уведомлений",
+	"notify_quiet_off":         "нет",
+	"notify_globally_off":      "⚠️ Уведомления администраторов отключены владельцем в /settings.",
+	"notify_usage":             "📝 Использование: /notify [now | digest <минуты> | mute | quiet <с>-<до> | quiet off]",
+	"notify_saved":             "✅ Настройки уведомлений сохранены",
+	"notify_error":             "❌ Ошибка при сохранении настроек уведомлений.",
+	"notify_unknown_mode":      "❌ Неизвестный режим уведомлений.",
+	"btn_notify_immediate":     "🔔 Сразу",
+	"btn_notify_digest":        "📬 Сводкой",
+	"btn_notify_muted":         "🔕 Выкл.",
+	"notify_digest_message":    "📬 Новых предложений: %d, самое старое ждёт %s.\n\nИспользуйте /proposals для просмотра.",
+	"age_minutes":              "%d мин",
+	"age_hours":                "%d ч %d мин",
+	"age_days":                 "%d дн %d ч",
+
+	"setting_stale_minutes":    "Напоминать о застоявшейся очереди через, мин (0 - выкл.)",
+	"setting_escalate_minutes": "Сообщать владельцу о застоявшейся очереди через, мин (0 - выкл.)",
+	"proposals_oldest":         "⏱ Самое старое ждёт %s.",
+	"queue_reminder":           "⏰ Очередь предложений застоялась: самое старое ждёт %s.\n\nИспользуйте /proposals для просмотра.",
+	"queue_escalation":         "🚨 Предложение #%d ждёт модерации уже %s. Модераторы не успевают - проверьте очередь: /proposals",
+
+	"setting_pending_ttl_days": "Снимать предложения с очереди через, дн. (0 - никогда)",
+	"setting_expire_notify":    "Сообщать автору о снятии предложения с очереди",
+	"status_expired":           "⌛ не рассмотрено вовремя",
+	"action_expire":            "снято с очереди по сроку",
+	"proposal_expired":         "⌛ Ваше предложение %s не было рассмотрено за %d дн. и снято с очереди. Вы можете отправить его снова.",
+
+	"setting_report_days": "Отчёт владельцу о модераторах раз в, дн. (0 - выкл.)",
+	"stats_error":         "❌ Ошибка при подсчёте статистики.",
+	"stats_report_title":  "📊 Отчёт за последние %d дн.",
+	"modstats_title":      "👥 Статистика модераторов",
+	"modstats_empty":      "👥 Модераторы ещё не рассмотрели ни одного предложения.",
+	"modstats_unknown":    "бывший модератор",
+	"modstats_line":       "✅ %d · ❌ %d · ⏱ медиана %s\n📅 за день %d · за неделю %d · за месяц %d",
+
+	"stats_empty":    "📊 Предложений пока не было.",
+	"stats_title":    "📊 Статистика предложений\n\nВсего: %d",
+	"stats_days":     "📅 По дням:",
+	"stats_weeks":    "🗓 По неделям:",
+	"stats_statuses": "По статусу:",
+	"stats_media":    "По типу:",
+	"stats_wait":     "⏱ Среднее ожидание решения: %s",
+	"stats_hours":    "🕒 Самые активные часы:",
+
+	"export_error":   "❌ Ошибка при выгрузке базы данных.",
+	"export_caption": "💾 Резервная копия: предложений %d, администраторов %d, настроек %d.\n\nВосстановление: ./bot import <файл.json>",
+
+	"search_usage":    "📝 Использование: /search [status:<статус>] [type:<тип>] [from:ГГГГ-ММ-ДД] [to:ГГГГ-ММ-ДД] <текст>\n\nПример: /search status:approved type:photo котики",
+	"search_error":    "❌ Ошибка при поиске предложений.",
+	"search_empty":    "🔎 Ничего не найдено.",
+	"search_results":  "🔎 Найдено: %d (страница %d из %d)",
+	"search_outdated": "❌ Результаты устарели, повторите /search",
+	"btn_search_prev": "◀️ Назад",
+	"btn_search_next": "Вперёд ▶️",
+
+	"setting_retention_rejected_days":  "Стирать отклонённые предложения через, дн. (0 - хранить)",
+	"setting_retention_published_days": "Обезличивать опубликованные предложения через, дн. (0 - хранить)",
+	"setting_retention_sender_days":    "Удалять ссылки на отправителей через, дн. (0 - хранить)",
+	"purge_error":                      "❌ Ошибка при проверке очистки данных.",
+	"purge_forever":                    "хранить всегда",
+	"purge_days":                       "%d дн.",
+	"purge_report": "🧹 Очистка по срокам хранения (пробный запуск, ничего не изменено)\n\n" +
+		"❌ Отклонённые, отозванные и просроченные (%s): будет стёрто содержимое %d\n" +
+		"✅ Опубликованные (%s): будет обезличено %d\n" +
+		"👤 Ссылки на отправителей (%s): будет удалено %d\n\n" +
+		"Очистка выполняется автоматически раз в час. Сроки меняются в /settings.",
+}
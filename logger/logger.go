@@ -0,0 +1,159 @@
+// Package logger настраивает структурированный журнал бота на основе log/slog.
+//
+// Бот обещает авторам анонимность, поэтому Telegram ID и текст предложений попадают
+// в журнал только через User и Content: в режиме приватности ID заменяется хешем,
+// а текст - его длиной, либо оба поля не выводятся вовсе.
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Режимы приватности
+const (
+	// PrivacyHash заменяет ID пользователей хешем, а текст - его длиной
+	PrivacyHash = "hash"
+	// PrivacyOmit не выводит ID пользователей и текст
+	PrivacyOmit = "omit"
+	// PrivacyOff выводит всё как есть, только для отладки
+	PrivacyOff = "off"
+)
+
+// Config - настройки журнала
+type Config struct {
+	// Level - debug, info, warn или error
+	Level string
+	// Format - text или json
+	Format string
+	// Privacy - hash, omit или off
+	Privacy string
+	// Salt - соль для хеширования ID. Без неё соль случайна, и хеши одного пользователя
+	// совпадают только в пределах одного запуска.
+	Salt string
+}
+
+// ConfigFromEnv читает настройки из LOG_LEVEL, LOG_FORMAT, LOG_PRIVACY и LOG_SALT
+func ConfigFromEnv() Config {
+	return Config{
+		Level:   os.Getenv("LOG_LEVEL"),
+		Format:  os.Getenv("LOG_FORMAT"),
+		Privacy: os.Getenv("LOG_PRIVACY"),
+		Salt:    os.Getenv("LOG_SALT"),
+	}
+}
+
+type privacy struct {
+	mode string
+	salt []byte
+}
+
+var current atomic.Pointer[privacy]
+
+func init() {
+	current.Store(&privacy{mode: PrivacyHash, salt: randomSalt()})
+}
+
+// Setup настраивает журнал по умолчанию для slog и стандартного пакета log
+func Setup(config Config) error {
+	return SetupWriter(os.Stderr, config)
+}
+
+// SetupWriter настраивает журнал с выводом в w
+func SetupWriter(w io.Writer, config Config) error {
+	var level slog.Level
+	if config.Level != "" {
+		if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+			return fmt.Errorf("некорректный LOG_LEVEL %q: %w", config.Level, err)
+		}
+	}
+
+	mode := strings.ToLower(config.Privacy)
+	switch mode {
+	case "":
+		mode = PrivacyHash
+	case PrivacyHash, PrivacyOmit, PrivacyOff:
+	default:
+		return fmt.Errorf("некорректный LOG_PRIVACY %q", config.Privacy)
+	}
+
+	salt := []byte(config.Salt)
+	if len(salt) == 0 {
+		salt = randomSalt()
+	}
+	current.Store(&privacy{mode: mode, salt: salt})
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("некорректный LOG_FORMAT %q", config.Format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Private сообщает, что включён режим приватности и данные пользователей выводить нельзя
+func Private() bool {
+	return current.Load().mode != PrivacyOff
+}
+
+// User - атрибут с Telegram ID пользователя, который выводится с учётом режима приватности
+func User(id int64) slog.Attr {
+	return slog.Any("user", userID(id))
+}
+
+// Content - атрибут с текстом предложения, который выводится с учётом режима приватности
+func Content(text string) slog.Attr {
+	return slog.Any("content", content(text))
+}
+
+type userID int64
+
+func (u userID) LogValue() slog.Value {
+	p := current.Load()
+	switch p.mode {
+	case PrivacyOff:
+		return slog.Int64Value(int64(u))
+	case PrivacyOmit:
+		// Пустая группа не выводится обработчиками slog
+		return slog.GroupValue()
+	default:
+		mac := hmac.New(sha256.New, p.salt)
+		mac.Write([]byte(strconv.FormatInt(int64(u), 10)))
+		return slog.StringValue(hex.EncodeToString(mac.Sum(nil))[:12])
+	}
+}
+
+type content string
+
+func (c content) LogValue() slog.Value {
+	switch current.Load().mode {
+	case PrivacyOff:
+		return slog.StringValue(string(c))
+	case PrivacyOmit:
+		return slog.GroupValue()
+	default:
+		return slog.StringValue(fmt.Sprintf("<%d chars>", len([]rune(string(c)))))
+	}
+}
+
+func randomSalt() []byte {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return salt
+}
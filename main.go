@@ -1,27 +1,46 @@
 package main
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"telegram-bot/bot"
+	"telegram-bot/logger"
 )
 
 const OWNER_ID = 6569505824
 
 func main() {
+	// LOG_LEVEL (debug, info, warn, error), LOG_FORMAT (text, json),
+	// LOG_PRIVACY (hash, omit, off) и LOG_SALT - см. пакет logger
+	if err := logger.Setup(logger.ConfigFromEnv()); err != nil {
+		slog.Error("Ошибка настройки журнала", "error", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		if err := runCLI(os.Args[1:]); err != nil {
+			slog.Error("Ошибка выполнения команды", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN не установлен")
+		slog.Error("TELEGRAM_BOT_TOKEN не установлен")
+		os.Exit(1)
 	}
 
 	channelID := int64(-1002431451231)
 
-	bot, err := bot.NewBot(token, channelID, OWNER_ID)
+	// DATABASE_URL - строка подключения PostgreSQL или путь к файлу SQLite, по умолчанию bot.db
+	bot, err := bot.NewBot(token, os.Getenv("DATABASE_URL"), channelID, OWNER_ID)
 	if err != nil {
-		log.Fatalf("Ошибка создания бота: %v", err)
+		slog.Error("Ошибка создания бота", "error", err)
+		os.Exit(1)
 	}
 
 	bot.Start()
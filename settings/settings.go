@@ -0,0 +1,248 @@
+package settings
+
+import (
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"telegram-bot/database"
+)
+
+const (
+	KeyChannelID       = "channel_id"
+	KeyNotifyAdmins    = "notify_admins"
+	KeyMaxTextLength   = "max_text_length"
+	KeyDefaultLang     = "default_lang"
+	KeyChannelLang     = "channel_lang"
+	KeySubmissionMode  = "submission_mode"
+	KeyDraftTimeout    = "draft_timeout_minutes"
+	KeyForwardPolicy   = "forward_policy"
+	KeyStagingChatID   = "staging_chat_id"
+	KeyPostHeader      = "post_header"
+	KeyPostFooter      = "post_footer"
+	KeyPostSignature   = "post_signature"
+	KeySuggestButton   = "suggest_button"
+	KeyUndoSeconds     = "undo_seconds"
+	KeyStaleMinutes    = "stale_minutes"
+	KeyEscalateMinutes = "escalate_minutes"
+	KeyPendingTTLDays  = "pending_ttl_days"
+	KeyExpireNotify    = "expire_notify"
+	KeyReportDays      = "report_days"
+
+	KeyRetentionRejected  = "retention_rejected_days"
+	KeyRetentionPublished = "retention_published_days"
+	KeyRetentionSender    = "retention_sender_days"
+
+	// Служебные настройки, не отображаются в /settings
+	KeySenderSecret = database.SenderSecretKey
+	KeyLastReportAt = "last_report_at"
+)
+
+const (
+	SubmissionInstant = "instant"
+	SubmissionConfirm = "confirm"
+	// SubmissionDraft собирает несколько сообщений в одно предложение
+	SubmissionDraft = "draft"
+)
+
+// Политика для пересланных сообщений
+const (
+	ForwardReject = "reject"
+	// ForwardShow принимает пересланное сообщение и показывает источник модераторам
+	ForwardShow = "show"
+	// ForwardAttribute дополнительно указывает источник в публикации
+	ForwardAttribute = "attribute"
+)
+
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindLanguage
+	KindChoice
+	// KindTemplate - текст с подстановками, может быть пустым
+	KindTemplate
+)
+
+// Definition описывает настройку, доступную для изменения через /settings.
+// Название настройки берётся из каталога строк по ключу "setting_<Key>",
+// названия вариантов KindChoice - по ключу "<Key>_<вариант>".
+type Definition struct {
+	Key     string
+	Kind    Kind
+	Default string
+	Options []string
+	// Unsigned запрещает отрицательные значения KindInt
+	Unsigned bool
+}
+
+var definitions = []Definition{
+	{Key: KeyChannelID, Kind: KindInt, Default: "0"},
+	{Key: KeyNotifyAdmins, Kind: KindBool, Default: "true"},
+	{Key: KeyMaxTextLength, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyDefaultLang, Kind: KindLanguage, Default: "ru"},
+	{Key: KeyChannelLang, Kind: KindLanguage, Default: "ru"},
+	{Key: KeySubmissionMode, Kind: KindChoice, Default: SubmissionInstant, Options: []string{SubmissionInstant, SubmissionConfirm, SubmissionDraft}},
+	{Key: KeyDraftTimeout, Kind: KindInt, Default: "10", Unsigned: true},
+	{Key: KeyForwardPolicy, Kind: KindChoice, Default: ForwardShow, Options: []string{ForwardReject, ForwardShow, ForwardAttribute}},
+	{Key: KeyStagingChatID, Kind: KindInt, Default: "0"},
+	{Key: KeyPostHeader, Kind: KindTemplate},
+	{Key: KeyPostFooter, Kind: KindTemplate},
+	{Key: KeyPostSignature, Kind: KindTemplate},
+	{Key: KeySuggestButton, Kind: KindBool, Default: "false"},
+	{Key: KeyUndoSeconds, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyStaleMinutes, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyEscalateMinutes, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyPendingTTLDays, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyExpireNotify, Kind: KindBool, Default: "false"},
+	{Key: KeyReportDays, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyRetentionRejected, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyRetentionPublished, Kind: KindInt, Default: "0", Unsigned: true},
+	{Key: KeyRetentionSender, Kind: KindInt, Default: "0", Unsigned: true},
+}
+
+// Definitions возвращает список настроек в порядке отображения в меню
+func Definitions() []Definition {
+	return definitions
+}
+
+// Lookup ищет описание настройки по ключу
+func Lookup(key string) (Definition, bool) {
+	for _, def := range definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Store читает настройки из базы данных и кэширует их до следующего изменения
+type Store struct {
+	db       database.Storage
+	mu       sync.RWMutex
+	cache    map[string]string
+	defaults map[string]string
+}
+
+func NewStore(db database.Storage) *Store {
+	defaults := make(map[string]string, len(definitions))
+	for _, def := range definitions {
+		defaults[def.Key] = def.Default
+	}
+
+	return &Store{
+		db:       db,
+		defaults: defaults,
+	}
+}
+
+// SetDefault переопределяет значение по умолчанию (например, из переменных окружения)
+func (s *Store) SetDefault(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults[key] = value
+}
+
+// Get возвращает текущее значение настройки или значение по умолчанию
+func (s *Store) Get(key string) string {
+	s.mu.RLock()
+	cache := s.cache
+	s.mu.RUnlock()
+
+	if cache == nil {
+		cache = s.load()
+	}
+
+	if value, ok := cache[key]; ok {
+		return value
+	}
+
+	return s.defaultValue(key)
+}
+
+func (s *Store) defaultValue(key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults[key]
+}
+
+// IsDefault сообщает, что настройка не задана в базе данных
+func (s *Store) IsDefault(key string) bool {
+	s.mu.RLock()
+	cache := s.cache
+	s.mu.RUnlock()
+
+	if cache == nil {
+		cache = s.load()
+	}
+
+	_, ok := cache[key]
+	return !ok
+}
+
+func (s *Store) Int(key string) int64 {
+	value, err := strconv.ParseInt(s.Get(key), 10, 64)
+	if err != nil {
+		slog.Warn("Некорректное значение настройки", "key", key, "error", err)
+		value, _ = strconv.ParseInt(s.defaultValue(key), 10, 64)
+	}
+	return value
+}
+
+func (s *Store) Bool(key string) bool {
+	value, err := strconv.ParseBool(s.Get(key))
+	if err != nil {
+		slog.Warn("Некорректное значение настройки", "key", key, "error", err)
+		value, _ = strconv.ParseBool(s.defaultValue(key))
+	}
+	return value
+}
+
+// Set сохраняет значение в базе данных и сбрасывает кэш
+func (s *Store) Set(key, value string) error {
+	if err := s.db.SetSetting(key, value); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Reset удаляет значение из базы данных, возвращая настройку к значению по умолчанию
+func (s *Store) Reset(key string) error {
+	if err := s.db.DeleteSetting(key); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *Store) ChannelID() int64 {
+	return s.Int(KeyChannelID)
+}
+
+func (s *Store) load() map[string]string {
+	cache := make(map[string]string)
+
+	stored, err := s.db.GetSettings()
+	if err != nil {
+		slog.Error("Ошибка загрузки настроек", "error", err)
+		return cache
+	}
+	for _, setting := range stored {
+		cache[setting.Key] = setting.Value
+	}
+
+	s.mu.Lock()
+	s.cache = cache
+	s.mu.Unlock()
+
+	return cache
+}
+
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}